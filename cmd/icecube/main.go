@@ -17,7 +17,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -26,13 +28,26 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 
+	"cloud.google.com/go/storage"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/hashicorp/vault/api"
+	"google.golang.org/api/option"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
 	"github.com/deptofdefense/icecube/pkg/fs"
+	"github.com/deptofdefense/icecube/pkg/localfs"
 	"github.com/deptofdefense/icecube/pkg/log"
+	"github.com/deptofdefense/icecube/pkg/playback"
 	"github.com/deptofdefense/icecube/pkg/server"
 	"github.com/deptofdefense/icecube/pkg/template"
 )
@@ -82,6 +97,26 @@ var (
 	}
 )
 
+const (
+	ACMEChallengeHTTP01    = "http-01"
+	ACMEChallengeTLSALPN01 = "tls-alpn-01"
+)
+
+var (
+	ACMEChallenges = []string{
+		ACMEChallengeHTTP01,
+		ACMEChallengeTLSALPN01,
+	}
+	// WellKnownACMEDirectories maps a short name to the directory URL of a well-known ACME CA, as listed by
+	// `defaults acme-directories`.
+	WellKnownACMEDirectories = map[string]string{
+		"letsencrypt":         "https://acme-v02.api.letsencrypt.org/directory",
+		"letsencrypt-staging": "https://acme-staging-v02.api.letsencrypt.org/directory",
+		"zerossl":             "https://acme.zerossl.com/v2/DV90",
+		"step-ca":             "https://localhost:9000/acme/acme/directory",
+	}
+)
+
 var (
 	DefaultCurveIDs = []string{
 		X25519,
@@ -103,6 +138,35 @@ var (
 	}
 )
 
+const (
+	ClientAuthNone    = "none"
+	ClientAuthRequest = "request"
+	ClientAuthRequire = "require"
+	ClientAuthVerify  = "verify"
+)
+
+var (
+	SupportedClientAuthTypes = []string{
+		ClientAuthNone,
+		ClientAuthRequest,
+		ClientAuthRequire,
+		ClientAuthVerify,
+	}
+	ClientAuthIdentifiers = map[string]tls.ClientAuthType{
+		ClientAuthNone:    tls.NoClientCert,
+		ClientAuthRequest: tls.RequestClientCert,
+		ClientAuthRequire: tls.RequireAnyClientCert,
+		ClientAuthVerify:  tls.RequireAndVerifyClientCert,
+	}
+)
+
+var (
+	SupportedOTelProtocols = []string{
+		server.OTLPProtocolGRPC,
+		server.OTLPProtocolHTTP,
+	}
+)
+
 func stringSliceContains(stringSlice []string, value string) bool {
 	for _, x := range stringSlice {
 		if value == x {
@@ -164,6 +228,10 @@ const (
 	flagTLSCipherSuites     = "tls-cipher-suites"
 	flagTLSCurvePreferences = "tls-curve-preferences"
 	//
+	flagClientCA    = "client-ca"
+	flagClientAuth  = "client-auth"
+	flagClientAuthz = "client-authz"
+	//
 	flagBehaviorNotFound = "behavior-not-found"
 	//
 	flagDirectoryIndex         = "directory-index"
@@ -172,6 +240,25 @@ const (
 	//
 	flagMaxDirectoryEntries = "max-directory-entries"
 	//
+	flagS3Gateway = "s3-gateway"
+	//
+	flagOTelEndpoint = "otel-endpoint"
+	flagOTelProtocol = "otel-protocol"
+	flagOTelSampler  = "otel-sampler"
+	flagOTelHeaders  = "otel-headers"
+	//
+	flagMetricsAddr = "metrics-addr"
+	//
+	flagCacheControl = "cache-control"
+	//
+	flagCacheSize          = "cache-size"
+	flagCacheTTL           = "cache-ttl"
+	flagCacheNegativeTTL   = "cache-negative-ttl"
+	flagCacheWarm          = "cache-warm"
+	flagCacheMaxObjectSize = "cache-max-object-size"
+	//
+	flagRecordPath = "record"
+	//
 	flagLogPath    = "log"
 	flagLogPerm    = "log-perm"
 	flagKeyLogPath = "keylog"
@@ -189,6 +276,28 @@ const (
 	flagAWSInsecureSkipVerify = "aws-insecure-skip-verify"
 	flagAWSS3Endpoint         = "aws-s3-endpoint"
 	flagAWSS3UsePathStyle     = "aws-s3-use-path-style"
+	//
+	flagGCSCredentialsFile = "gcs-credentials-file"
+	//
+	flagAzureAccountName      = "azure-account-name"
+	flagAzureAccountKey       = "azure-account-key"
+	flagAzureConnectionString = "azure-connection-string"
+	//
+	flagVaultAddress = "vault-address"
+	flagVaultToken   = "vault-token"
+	//
+	flagPlaybackTarget         = "target"
+	flagPlaybackWorkers        = "workers"
+	flagPlaybackRampUp         = "ramp-up"
+	flagPlaybackPreserveTiming = "preserve-timing"
+	//
+	flagACME             = "acme"
+	flagACMEDirectoryURL = "acme-directory-url"
+	flagACMEEmail        = "acme-email"
+	flagACMEAgreeTOS     = "acme-agree-tos"
+	flagACMEChallenge    = "acme-challenge"
+	flagACMECacheDir     = "acme-cache-dir"
+	flagACMEAllowedHosts = "acme-allowed-hosts"
 )
 
 type File struct {
@@ -205,8 +314,8 @@ func initServeFlags(flag *pflag.FlagSet) {
 	flag.String(flagDefaultServerCert, "", "path to default server public cert")
 	flag.String(flagDefaultServerKey, "", "path to default server private key")
 	flag.String(flagServerKeyPairs, "", "additional server key pairs in the format of a json array of arrays [[path to server public cert, path to server private key],...]")
-	flag.StringP(flagRootPath, "r", "", "path to the default document root served")
-	flag.String(flagFileSystems, "", "additional file systems in the format of a json array of strings")
+	flag.StringP(flagRootPath, "r", "", "path to the default document root served.  A bare path or file:// prefix is local; s3://, gs://, az://, and vault:// are also supported.")
+	flag.String(flagFileSystems, "", "additional file systems in the format of a json array of strings.  Each accepts the same schemes as --root.")
 	flag.String(flagSites, "", "sites hosted by the server in the format of a json map of server name to file system")
 	flag.StringP(flagLogPath, "l", "-", "path to the log output.  Defaults to stdout.")
 	flag.String(flagLogPerm, "0600", "file permissions for log output file as unix file mode.")
@@ -215,12 +324,35 @@ func initServeFlags(flag *pflag.FlagSet) {
 	flag.String(flagDirectoryTemplate, "", "path to directory template")
 	flag.Bool(flagDirectoryTrailingSlash, false, "append trailing slash to directories")
 	flag.Int(flagMaxDirectoryEntries, -1, "maximum directory entries returned")
+	flag.String(flagS3Gateway, "", "path prefix under which to serve an S3-compatible gateway to the configured file systems.  Disabled if empty.")
+	flag.String(flagCacheControl, server.DefaultCacheControl, "value of the Cache-Control header set on served files")
+	flag.Int(flagCacheSize, 0, "maximum number of Stat/Open results to cache in memory per file system, keyed by path.  0 disables caching.")
+	flag.String(flagCacheTTL, "1m", "how long a cached Stat/Open result is trusted before being re-fetched")
+	flag.String(flagCacheNegativeTTL, "10s", "how long a cached \"not found\" Stat result is trusted before being re-checked")
+	flag.Bool(flagCacheWarm, false, "walk every file system and pre-populate the cache on startup.  Requires cache-size to be greater than 0.")
+	flag.Int64(flagCacheMaxObjectSize, fs.DefaultMaxCachedObjectSize, "largest object content, in bytes, to buffer into the cache.  Larger objects are served directly from the backing file system on every request, uncached.")
+	flag.String(flagOTelEndpoint, "", "OTLP collector endpoint that request trace spans are exported to, e.g. \"localhost:4317\".  Disabled if empty.")
+	flag.String(flagOTelProtocol, server.OTLPProtocolGRPC, "OTLP exporter protocol.  One of: "+strings.Join(SupportedOTelProtocols, ","))
+	flag.Float64(flagOTelSampler, 1, "fraction of requests traced, between 0 and 1")
+	flag.String(flagOTelHeaders, "", "additional headers sent with every OTLP export, in the format of a json map of header name to value")
+	flag.String(flagMetricsAddr, "", "address that a /metrics endpoint exposing Prometheus metrics will listen on, separate from --addr.  Disabled if empty.")
+	flag.String(flagRecordPath, "", "path to a JSONL file that every served request is recorded to, for later replay with the playback command.  Disabled if empty.")
+	flag.Bool(flagACME, false, "provision the server certificate automatically via ACME (e.g. Let's Encrypt) instead of --server-cert/--server-key-pairs")
+	flag.String(flagACMEDirectoryURL, "", "ACME directory URL.  Defaults to the Let's Encrypt production directory if empty.")
+	flag.String(flagACMEEmail, "", "contact email given to the ACME CA")
+	flag.Bool(flagACMEAgreeTOS, false, "agree to the ACME CA's terms of service")
+	flag.String(flagACMEChallenge, ACMEChallengeHTTP01, "ACME challenge type to use.  One of: "+strings.Join(ACMEChallenges, ","))
+	flag.String(flagACMECacheDir, "", "directory, or s3:// prefix, that ACME-issued certificates are cached in")
+	flag.String(flagACMEAllowedHosts, "", "comma-separated allowlist of hostnames ACME is permitted to issue for.  Defaults to the hostnames in --sites.")
 	flag.String(flagBehaviorNotFound, BehaviorNone, "default behavior when a file is not found.  One of: "+strings.Join(NotFoundBehaviors, ","))
 	initTimeoutFlags(flag)
 	initTLSFlags(flag)
 	flag.Bool(flagUnsafe, false, "allow unsafe configuration")
 	flag.Bool(flagDryRun, false, "exit after checking configuration")
 	initAWSFlags(flag)
+	initGCSFlags(flag)
+	initAzureFlags(flag)
+	initVaultFlags(flag)
 }
 
 func initTimeoutFlags(flag *pflag.FlagSet) {
@@ -234,6 +366,9 @@ func initTLSFlags(flag *pflag.FlagSet) {
 	flag.String(flagTLSMaxVersion, TLSVersion1_3, "maximum TLS version accepted for requests")
 	flag.String(flagTLSCipherSuites, "", "list of supported cipher suites for TLS versions up to 1.2 (TLS 1.3 is not configurable)")
 	flag.String(flagTLSCurvePreferences, strings.Join(DefaultCurveIDs, ","), "curve preferences")
+	flag.String(flagClientCA, "", "path to a PEM file of CA certificates trusted to sign client certificates for mTLS")
+	flag.String(flagClientAuth, ClientAuthNone, "client certificate authentication mode.  One of: "+strings.Join(SupportedClientAuthTypes, ","))
+	flag.String(flagClientAuthz, "", "path to a JSON file mapping a SPIFFE ID or subject DN pattern to the sites, path prefixes, and methods that caller is authorized for.  Requires client-auth to be \"require\" or \"verify\".")
 }
 
 func initAWSFlags(flag *pflag.FlagSet) {
@@ -249,6 +384,21 @@ func initAWSFlags(flag *pflag.FlagSet) {
 	flag.Bool(flagAWSS3UsePathStyle, false, "Use path-style addressing (default is to use virtual-host-style addressing)")
 }
 
+func initGCSFlags(flag *pflag.FlagSet) {
+	flag.String(flagGCSCredentialsFile, "", "path to a Google Cloud service account credentials file.  Uses application default credentials if empty.")
+}
+
+func initAzureFlags(flag *pflag.FlagSet) {
+	flag.String(flagAzureAccountName, "", "Azure Storage account name")
+	flag.String(flagAzureAccountKey, "", "Azure Storage account key")
+	flag.String(flagAzureConnectionString, "", "Azure Storage connection string.  Overrides azure-account-name and azure-account-key if set.")
+}
+
+func initVaultFlags(flag *pflag.FlagSet) {
+	flag.String(flagVaultAddress, "", "HashiCorp Vault server address.  Defaults to the VAULT_ADDR environment variable.")
+	flag.String(flagVaultToken, "", "HashiCorp Vault token.  Defaults to the VAULT_TOKEN environment variable.")
+}
+
 func initViper(cmd *cobra.Command) (*viper.Viper, error) {
 	v := viper.New()
 	err := v.BindPFlags(cmd.Flags())
@@ -260,7 +410,7 @@ func initViper(cmd *cobra.Command) (*viper.Viper, error) {
 	return v, nil
 }
 
-func initS3Client(v *viper.Viper) *s3.Client {
+func initS3Client(v fs.Config) *s3.Client {
 	accessKeyID := v.GetString(flagAWSAccessKeyID)
 	secretAccessKey := v.GetString(flagAWSSecretAccessKey)
 	sessionToken := v.GetString(flagAWSSessionToken)
@@ -340,7 +490,7 @@ func checkConfig(v *viper.Viper) error {
 		if err := json.Unmarshal([]byte(serverKeyPairs), &([][2]string{})); err != nil {
 			return fmt.Errorf("invalid format for server key pairs %s: %w", serverKeyPairs, err)
 		}
-	} else {
+	} else if !v.GetBool(flagACME) {
 		defaultServerCert := v.GetString(flagDefaultServerCert)
 		if len(defaultServerCert) == 0 {
 			return fmt.Errorf("default server cert is missing")
@@ -350,15 +500,37 @@ func checkConfig(v *viper.Viper) error {
 			return fmt.Errorf("default server key is missing")
 		}
 	}
+
+	if v.GetBool(flagACME) {
+		if !v.GetBool(flagACMEAgreeTOS) {
+			return fmt.Errorf("acme-agree-tos is required when acme is enabled")
+		}
+		if len(v.GetString(flagACMECacheDir)) == 0 {
+			return fmt.Errorf("acme-cache-dir is required when acme is enabled")
+		}
+		challenge := v.GetString(flagACMEChallenge)
+		if !stringSliceContains(ACMEChallenges, challenge) {
+			return fmt.Errorf("invalid acme challenge %q, must be one of: %s", challenge, strings.Join(ACMEChallenges, ","))
+		}
+	}
+	rootPath := v.GetString(flagRootPath)
 	fileSystems := v.GetString(flagFileSystems)
+	fileSystemPaths := []string{}
 	if len(fileSystems) > 0 {
-		if err := json.Unmarshal([]byte(fileSystems), &([]string{})); err != nil {
+		if err := json.Unmarshal([]byte(fileSystems), &fileSystemPaths); err != nil {
 			return fmt.Errorf("invalid format for file systems: %w", err)
 		}
-	} else {
-		rootPath := v.GetString(flagRootPath)
-		if len(rootPath) == 0 {
-			return fmt.Errorf("root path is missing")
+	} else if len(rootPath) == 0 {
+		return fmt.Errorf("root path is missing")
+	}
+	if len(rootPath) > 0 {
+		if scheme := fs.Scheme(rootPath); !fs.Registered(scheme) {
+			return fmt.Errorf("no file system registered for scheme %q in root path %q", scheme, rootPath)
+		}
+	}
+	for _, fileSystemPath := range fileSystemPaths {
+		if scheme := fs.Scheme(fileSystemPath); !fs.Registered(scheme) {
+			return fmt.Errorf("no file system registered for scheme %q in file system path %q", scheme, fileSystemPath)
 		}
 	}
 
@@ -414,6 +586,44 @@ func checkConfig(v *viper.Viper) error {
 	if timeoutIdleDuration < 5*time.Second || timeoutIdleDuration > 30*time.Minute {
 		return fmt.Errorf("invalid idle timeout %q, must be greater than or equal to 5 seconds and less than or equal to 30 minutes", timeoutIdleDuration)
 	}
+	cacheSize := v.GetInt(flagCacheSize)
+	if cacheSize < 0 {
+		return fmt.Errorf("cache size must be greater than or equal to 0")
+	}
+	if _, err := time.ParseDuration(v.GetString(flagCacheTTL)); err != nil {
+		return fmt.Errorf("error parsing cache ttl: %w", err)
+	}
+	if _, err := time.ParseDuration(v.GetString(flagCacheNegativeTTL)); err != nil {
+		return fmt.Errorf("error parsing cache negative ttl: %w", err)
+	}
+	if v.GetBool(flagCacheWarm) && cacheSize == 0 {
+		return fmt.Errorf("cache-warm requires cache-size to be greater than 0")
+	}
+	if otelEndpoint := v.GetString(flagOTelEndpoint); len(otelEndpoint) > 0 {
+		otelProtocol := v.GetString(flagOTelProtocol)
+		if !stringSliceContains(SupportedOTelProtocols, otelProtocol) {
+			return fmt.Errorf("invalid otel protocol %q, must be one of: %s", otelProtocol, strings.Join(SupportedOTelProtocols, ","))
+		}
+		if _, err := url.Parse("otlp://" + otelEndpoint); err != nil {
+			return fmt.Errorf("invalid otel endpoint %q: %w", otelEndpoint, err)
+		}
+		if otelSampler := v.GetFloat64(flagOTelSampler); otelSampler < 0 || otelSampler > 1 {
+			return fmt.Errorf("otel sampler must be between 0 and 1")
+		}
+		if otelHeaders := v.GetString(flagOTelHeaders); len(otelHeaders) > 0 {
+			if err := json.Unmarshal([]byte(otelHeaders), &(map[string]string{})); err != nil {
+				return fmt.Errorf("invalid format for otel headers: %w", err)
+			}
+		}
+	}
+	if metricsAddr := v.GetString(flagMetricsAddr); len(metricsAddr) > 0 {
+		if metricsAddr == addr {
+			return fmt.Errorf("metrics-addr must not be the same address as addr")
+		}
+		if metricsAddr == v.GetString(flagRedirectAddress) {
+			return fmt.Errorf("metrics-addr must not be the same address as redirect")
+		}
+	}
 	if err := checkTLSConfig(v); err != nil {
 		return fmt.Errorf("error with TLS configuration: %w", err)
 	}
@@ -444,6 +654,26 @@ func checkTLSConfig(v *viper.Viper) error {
 			return fmt.Errorf("invalid curve preference %q", curveID)
 		}
 	}
+	clientAuth := v.GetString(flagClientAuth)
+	if !stringSliceContains(SupportedClientAuthTypes, clientAuth) {
+		return fmt.Errorf("invalid client auth %q, must be one of: %s", clientAuth, strings.Join(SupportedClientAuthTypes, ","))
+	}
+	if clientAuth != ClientAuthNone && len(v.GetString(flagClientCA)) == 0 {
+		return fmt.Errorf("client-ca is required when client-auth is %q", clientAuth)
+	}
+	if clientAuthzPath := v.GetString(flagClientAuthz); len(clientAuthzPath) > 0 {
+		if clientAuth != ClientAuthRequire && clientAuth != ClientAuthVerify {
+			return fmt.Errorf("client-authz requires client-auth to be %q or %q", ClientAuthRequire, ClientAuthVerify)
+		}
+		f, err := os.Open(clientAuthzPath)
+		if err != nil {
+			return fmt.Errorf("error opening client authorization policy %q: %w", clientAuthzPath, err)
+		}
+		defer f.Close()
+		if _, err := server.LoadAuthzPolicy(f); err != nil {
+			return fmt.Errorf("error loading client authorization policy %q: %w", clientAuthzPath, err)
+		}
+	}
 	return nil
 }
 
@@ -455,6 +685,54 @@ func newTraceID() string {
 	return traceID.String()
 }
 
+// initTracerProvider returns the TracerProvider that request spans are started from, or nil if --otel-endpoint is
+// unset.  The caller is responsible for calling otel.SetTracerProvider with a non-nil result.
+func initTracerProvider(ctx context.Context, v *viper.Viper) (*sdktrace.TracerProvider, error) {
+	endpoint := v.GetString(flagOTelEndpoint)
+	if len(endpoint) == 0 {
+		return nil, nil
+	}
+	headers := map[string]string{}
+	if headersString := v.GetString(flagOTelHeaders); len(headersString) > 0 {
+		if err := json.Unmarshal([]byte(headersString), &headers); err != nil {
+			return nil, fmt.Errorf("error unmarshaling otel headers: %w", err)
+		}
+	}
+	return server.NewTracerProvider(ctx, endpoint, v.GetString(flagOTelProtocol), v.GetFloat64(flagOTelSampler), headers)
+}
+
+// statWithCacheHit calls Stat on fileSystem, also reporting whether the result came from an fs.CachingFileSystem's
+// cache, so request tracing can record a cache hit/miss without fileSystem's static type being known here.
+func statWithCacheHit(ctx context.Context, fileSystem fs.FileSystem, name string) (fs.FileInfo, bool, error) {
+	if cachingFileSystem, ok := fileSystem.(*fs.CachingFileSystem); ok {
+		return cachingFileSystem.StatCached(ctx, name)
+	}
+	fi, err := fileSystem.Stat(ctx, name)
+	return fi, false, err
+}
+
+// countingResponseWriter wraps an http.ResponseWriter to record the status code and body byte count of a response,
+// for request metrics and tracing that the standard library's http.ServeContent doesn't otherwise expose.
+type countingResponseWriter struct {
+	http.ResponseWriter
+	statusCode   int
+	bytesWritten int64
+}
+
+func (w *countingResponseWriter) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *countingResponseWriter) Write(p []byte) (int, error) {
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.bytesWritten += int64(n)
+	return n, err
+}
+
 func initLogger(path string, perm string) (*log.SimpleLogger, error) {
 
 	if path == "-" {
@@ -570,19 +848,58 @@ func buildNameToCertificate(defaultCertificate *tls.Certificate, certificates []
 	return nameToCertificate, nil
 }
 
-func initTLSConfig(v *viper.Viper, defaultCertificate *tls.Certificate, certificates []tls.Certificate, minVersion string, maxVersion string, cipherSuites []uint16, keyLogger io.Writer) (*tls.Config, error) {
+// initClientCAs loads the PEM-encoded CA bundle named by --client-ca, for verifying client certificates under
+// mTLS.  It returns nil if --client-ca is unset.
+func initClientCAs(v *viper.Viper) (*x509.CertPool, error) {
+	clientCAPath := v.GetString(flagClientCA)
+	if len(clientCAPath) == 0 {
+		return nil, nil
+	}
+	pemBytes, err := os.ReadFile(clientCAPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading client CA bundle %q: %w", clientCAPath, err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pemBytes) {
+		return nil, fmt.Errorf("no certificates found in client CA bundle %q", clientCAPath)
+	}
+	return pool, nil
+}
+
+// initClientAuthzPolicy loads the client authorization policy named by --client-authz.  It returns nil if
+// --client-authz is unset.
+func initClientAuthzPolicy(v *viper.Viper) (server.AuthzPolicy, error) {
+	clientAuthzPath := v.GetString(flagClientAuthz)
+	if len(clientAuthzPath) == 0 {
+		return nil, nil
+	}
+	f, err := os.Open(clientAuthzPath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening client authorization policy %q: %w", clientAuthzPath, err)
+	}
+	defer f.Close()
+	policy, err := server.LoadAuthzPolicy(f)
+	if err != nil {
+		return nil, fmt.Errorf("error loading client authorization policy %q: %w", clientAuthzPath, err)
+	}
+	return policy, nil
+}
+
+func initTLSConfig(v *viper.Viper, defaultCertificate *tls.Certificate, certificates []tls.Certificate, minVersion string, maxVersion string, cipherSuites []uint16, keyLogger io.Writer, acmeManager *autocert.Manager, clientCAs *x509.CertPool, clientAuth tls.ClientAuthType, clientAuthzPolicy server.AuthzPolicy) (*tls.Config, error) {
 
 	config := &tls.Config{
 		MinVersion:   TLSVersionIdentifiers[minVersion],
 		MaxVersion:   TLSVersionIdentifiers[maxVersion],
 		KeyLogWriter: keyLogger,
 	}
+
+	var staticGetCertificate func(*tls.ClientHelloInfo) (*tls.Certificate, error)
 	if len(certificates) > 0 {
 		nameToCertificate, err := buildNameToCertificate(defaultCertificate, certificates)
 		if err != nil {
 			return nil, fmt.Errorf("error building name to certificate map: %w", err)
 		}
-		config.GetCertificate = func(clientHelloInfo *tls.ClientHelloInfo) (*tls.Certificate, error) {
+		staticGetCertificate = func(clientHelloInfo *tls.ClientHelloInfo) (*tls.Certificate, error) {
 			if len(clientHelloInfo.ServerName) == 0 {
 				if defaultCertificate != nil {
 					return defaultCertificate, nil
@@ -597,10 +914,27 @@ func initTLSConfig(v *viper.Viper, defaultCertificate *tls.Certificate, certific
 			}
 			return &certificates[0], nil
 		}
-	} else {
+	} else if defaultCertificate != nil {
 		config.Certificates = []tls.Certificate{*defaultCertificate}
 	}
 
+	if acmeManager != nil {
+		// buildNameToCertificate is bypassed for any host acmeManager's HostPolicy allows; a host outside that
+		// allowlist falls back to the statically configured certificates, if any were given.
+		config.GetCertificate = func(clientHelloInfo *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			if acmeManager.HostPolicy == nil || acmeManager.HostPolicy(clientHelloInfo.Context(), clientHelloInfo.ServerName) == nil {
+				return acmeManager.GetCertificate(clientHelloInfo)
+			}
+			if staticGetCertificate != nil {
+				return staticGetCertificate(clientHelloInfo)
+			}
+			return nil, fmt.Errorf("no certificate configured for %q", clientHelloInfo.ServerName)
+		}
+		config.NextProtos = append(config.NextProtos, acme.ALPNProto)
+	} else if staticGetCertificate != nil {
+		config.GetCertificate = staticGetCertificate
+	}
+
 	if len(cipherSuites) > 0 {
 		config.CipherSuites = cipherSuites
 	}
@@ -612,74 +946,316 @@ func initTLSConfig(v *viper.Viper, defaultCertificate *tls.Certificate, certific
 		}
 		config.CurvePreferences = curvePreferences
 	}
-	return config, nil
-}
 
-func initFileSystem(ctx context.Context, rootPath string, s3Client *s3.Client, maxDirectoryEntries int) fs.FileSystem {
-	if strings.HasPrefix(rootPath, "s3://") {
-		rootParts := strings.Split(rootPath[len("s3://"):], "/")
-		bucket := rootParts[0]
-		prefix := strings.Join(rootParts[1:], "/")
-		bucketCreationDate := time.Now()
-		listBucketsOutput, err := s3Client.ListBuckets(ctx, &s3.ListBucketsInput{})
-		if err == nil {
-			for _, b := range listBucketsOutput.Buckets {
-				if bucket == aws.ToString(b.Name) {
-					bucketCreationDate = aws.ToTime(b.CreationDate)
-					break
+	if clientCAs != nil {
+		config.ClientCAs = clientCAs
+		config.ClientAuth = clientAuth
+	}
+
+	if clientAuthzPolicy != nil {
+		// VerifyConnection rejects any caller whose identity matches nothing in the policy at all.  It can't
+		// check the site/path-prefix/methods tuple of an AuthzRule, since the HTTP request that determines those
+		// hasn't been read yet at handshake time; the request handler re-extracts the same CallerIdentity from
+		// r.TLS and calls clientAuthzPolicy.Allowed with the site, path, and method once it knows them.
+		config.VerifyConnection = func(cs tls.ConnectionState) error {
+			identity := server.ExtractCallerIdentity(&cs)
+			if !clientAuthzPolicy.Known(identity) {
+				versionName := ""
+				for name, id := range TLSVersionIdentifiers {
+					if id == cs.Version {
+						versionName = name
+						break
+					}
 				}
+				server.TLSHandshakeFailures.WithLabelValues(versionName, tls.CipherSuiteName(cs.CipherSuite)).Inc()
+				return fmt.Errorf("caller %q is not authorized by any client authorization policy rule", identity.String())
 			}
+			return nil
 		}
-		return fs.NewS3FileSystem(bucket, prefix, s3Client, bucketCreationDate, maxDirectoryEntries)
 	}
 
-	return fs.NewLocalFileSystem(rootPath)
+	return config, nil
 }
 
-func initFileSystems(ctx context.Context, v *viper.Viper, maxDirectoryEntries int) (map[string]fs.FileSystem, error) {
-	rootPath := v.GetString(flagRootPath)
-	fileSystemPathsString := v.GetString(flagFileSystems)
-	fileSystemPathsSlice := []string{}
-	if len(fileSystemPathsString) > 0 {
-		err := json.Unmarshal([]byte(fileSystemPathsString), &fileSystemPathsSlice)
-		if err != nil {
-			return nil, fmt.Errorf("invalid format for file systems: %w", err)
+// acmeCacheFileSystem is the subset of fs.WritableFileSystem that fsACMECache needs, so it can adapt a
+// LocalFileSystem or S3FileSystem to autocert.Cache without depending on the rest of that interface.
+type acmeCacheFileSystem interface {
+	Open(ctx context.Context, name string) (io.ReadSeeker, error)
+	Create(ctx context.Context, name string) (io.WriteCloser, error)
+	Remove(ctx context.Context, name string) error
+	Join(name ...string) string
+	IsNotExist(err error) bool
+}
+
+// fsACMECache adapts an acmeCacheFileSystem to autocert.Cache, so ACME-issued certificates can be cached on a
+// LocalFileSystem or S3FileSystem interchangeably with the rest of icecube's storage configuration.
+type fsACMECache struct {
+	fs     acmeCacheFileSystem
+	prefix string
+}
+
+func (c *fsACMECache) Get(ctx context.Context, name string) ([]byte, error) {
+	rs, err := c.fs.Open(ctx, c.fs.Join(c.prefix, name))
+	if err != nil {
+		if c.fs.IsNotExist(err) {
+			return nil, autocert.ErrCacheMiss
 		}
+		return nil, err
 	}
+	return io.ReadAll(rs)
+}
 
-	s3ClientNeeded := false
-	if strings.HasPrefix(rootPath, "s3://") {
-		s3ClientNeeded = true
+func (c *fsACMECache) Put(ctx context.Context, name string, data []byte) error {
+	w, err := c.fs.Create(ctx, c.fs.Join(c.prefix, name))
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		_ = w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func (c *fsACMECache) Delete(ctx context.Context, name string) error {
+	return c.fs.Remove(ctx, c.fs.Join(c.prefix, name))
+}
+
+// initACMEManager returns an autocert.Manager configured from the acme-* flags, or nil if ACME is disabled.  The
+// allowed hosts it issues for come from --acme-allowed-hosts if set, otherwise from the hostnames in sites, so
+// issuance can't be triggered for arbitrary SNI values sent by a client.
+func initACMEManager(v *viper.Viper, sites map[string]string) (*autocert.Manager, error) {
+	if !v.GetBool(flagACME) {
+		return nil, nil
+	}
+
+	var allowedHosts []string
+	if allowedHostsString := v.GetString(flagACMEAllowedHosts); len(allowedHostsString) > 0 {
+		allowedHosts = strings.Split(allowedHostsString, ",")
+	} else {
+		for host := range sites {
+			allowedHosts = append(allowedHosts, host)
+		}
+	}
+	if len(allowedHosts) == 0 {
+		return nil, fmt.Errorf("acme requires at least one allowed host, derived from --sites or set explicitly with --acme-allowed-hosts")
+	}
+
+	cachePath := v.GetString(flagACMECacheDir)
+	var cache autocert.Cache
+	if strings.HasPrefix(cachePath, "s3://") {
+		s3Client := initS3Client(v)
+		cacheParts := strings.SplitN(strings.TrimPrefix(cachePath, "s3://"), "/", 2)
+		cacheBucket := cacheParts[0]
+		cachePrefix := ""
+		if len(cacheParts) > 1 {
+			cachePrefix = cacheParts[1]
+		}
+		cache = &fsACMECache{fs: fs.NewS3FileSystem(cacheBucket, cachePrefix, s3Client, time.Now(), -1)}
 	} else {
-		for _, str := range fileSystemPathsSlice {
-			if strings.HasPrefix(str, "s3://") {
-				s3ClientNeeded = true
+		cache = autocert.DirCache(cachePath)
+	}
+
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		Cache:      cache,
+		HostPolicy: autocert.HostWhitelist(allowedHosts...),
+		Email:      v.GetString(flagACMEEmail),
+	}
+	if directoryURL := v.GetString(flagACMEDirectoryURL); len(directoryURL) > 0 {
+		manager.Client = &acme.Client{DirectoryURL: directoryURL}
+	}
+	return manager, nil
+}
+
+// init registers icecube's built-in file system schemes with pkg/fs, so initFileSystems can dispatch on a root
+// path's "scheme://" prefix instead of hard-coding each backend.  Downstream users of icecube as a library can add
+// their own proprietary backends with an fs.Register call of their own, without forking this file.
+func init() {
+	fs.Register("file", fileOpener)
+	fs.Register("s3", s3Opener)
+	fs.Register("gs", gcsOpener)
+	fs.Register("az", azureOpener)
+	fs.Register("vault", vaultOpener)
+	fs.Register("mem", memOpener)
+	fs.Register("localfs", localFSOpener)
+}
+
+// fileOpener opens a local directory rooted at rawPath's path, e.g. "file:///www" or the bare path "/www".
+func fileOpener(ctx context.Context, rawPath string, config fs.Config) (fs.FileSystem, error) {
+	return fs.NewLocalFileSystem(strings.TrimPrefix(rawPath, "file://")), nil
+}
+
+// s3Opener opens an S3FileSystem rooted at the bucket and prefix in rawPath, e.g. "s3://bucket/prefix".
+func s3Opener(ctx context.Context, rawPath string, config fs.Config) (fs.FileSystem, error) {
+	rootParts := strings.Split(strings.TrimPrefix(rawPath, "s3://"), "/")
+	bucket := rootParts[0]
+	prefix := strings.Join(rootParts[1:], "/")
+
+	s3Client := initS3Client(config)
+	bucketCreationDate := time.Now()
+	listBucketsOutput, err := s3Client.ListBuckets(ctx, &s3.ListBucketsInput{})
+	if err == nil {
+		for _, b := range listBucketsOutput.Buckets {
+			if bucket == aws.ToString(b.Name) {
+				bucketCreationDate = aws.ToTime(b.CreationDate)
 				break
 			}
 		}
 	}
+	return fs.NewS3FileSystem(bucket, prefix, s3Client, bucketCreationDate, config.GetInt(flagMaxDirectoryEntries)), nil
+}
+
+// gcsOpener opens a GCSFileSystem rooted at the bucket and prefix in rawPath, e.g. "gs://bucket/prefix".  It
+// authenticates with the service account credentials file named by --gcs-credentials-file, falling back to
+// application default credentials if that flag is unset.
+func gcsOpener(ctx context.Context, rawPath string, config fs.Config) (fs.FileSystem, error) {
+	rootParts := strings.Split(strings.TrimPrefix(rawPath, "gs://"), "/")
+	bucket := rootParts[0]
+	prefix := strings.Join(rootParts[1:], "/")
+
+	var opts []option.ClientOption
+	if credentialsFile := config.GetString(flagGCSCredentialsFile); len(credentialsFile) > 0 {
+		opts = append(opts, option.WithCredentialsFile(credentialsFile))
+	}
+	client, err := storage.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("error creating GCS client: %w", err)
+	}
+	return fs.NewGCSFileSystem(client, bucket, prefix), nil
+}
+
+// azureOpener opens an AzureFileSystem rooted at the container and prefix in rawPath, e.g. "az://container/prefix".
+// It authenticates with --azure-connection-string if set, otherwise with the shared key pair named by
+// --azure-account-name and --azure-account-key.
+func azureOpener(ctx context.Context, rawPath string, config fs.Config) (fs.FileSystem, error) {
+	rootParts := strings.Split(strings.TrimPrefix(rawPath, "az://"), "/")
+	containerName := rootParts[0]
+	prefix := strings.Join(rootParts[1:], "/")
+
+	var client *azblob.Client
+	var err error
+	if connectionString := config.GetString(flagAzureConnectionString); len(connectionString) > 0 {
+		client, err = azblob.NewClientFromConnectionString(connectionString, nil)
+	} else {
+		accountName := config.GetString(flagAzureAccountName)
+		var cred *azblob.SharedKeyCredential
+		cred, err = azblob.NewSharedKeyCredential(accountName, config.GetString(flagAzureAccountKey))
+		if err == nil {
+			serviceURL := fmt.Sprintf("https://%s.blob.core.windows.net/", accountName)
+			client, err = azblob.NewClientWithSharedKeyCredential(serviceURL, cred, nil)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error creating Azure Blob Storage client: %w", err)
+	}
+	return fs.NewAzureFileSystem(client, containerName, prefix), nil
+}
+
+// vaultOpener opens a VaultFileSystem rooted at the KV v2 mount and prefix in rawPath, e.g. "vault://secret/tls".
+// It authenticates with --vault-address and --vault-token, falling back to the VAULT_ADDR and VAULT_TOKEN
+// environment variables respected by api.DefaultConfig if those flags are unset.
+func vaultOpener(ctx context.Context, rawPath string, config fs.Config) (fs.FileSystem, error) {
+	rootParts := strings.Split(strings.TrimPrefix(rawPath, "vault://"), "/")
+	mount := rootParts[0]
+	prefix := strings.Join(rootParts[1:], "/")
+
+	vaultConfig := api.DefaultConfig()
+	if address := config.GetString(flagVaultAddress); len(address) > 0 {
+		vaultConfig.Address = address
+	}
+	client, err := api.NewClient(vaultConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error creating Vault client: %w", err)
+	}
+	if token := config.GetString(flagVaultToken); len(token) > 0 {
+		client.SetToken(token)
+	}
+	return fs.NewVaultFileSystem(client, mount, prefix), nil
+}
 
-	var s3Client *s3.Client
+// memOpener opens a fresh, empty in-memory file system, e.g. "mem://" or "mem://anything" (the host part, if any,
+// is ignored).  It is meant for tests and other short-lived processes: content never leaves process memory, and a
+// distinct MemFileSystem is created on every call, so two "mem://" roots in --file-systems never share state.
+func memOpener(ctx context.Context, rawPath string, config fs.Config) (fs.FileSystem, error) {
+	return fs.NewMemFileSystem(), nil
+}
 
-	if s3ClientNeeded {
-		s3Client = initS3Client(v)
+// localFSOpener opens a localfs.LocalFileSystem rooted at rawPath's path, e.g. "localfs:///www".  Unlike
+// fileOpener's afero-backed fs.LocalFileSystem, every path it serves is resolved (symlinks included) and checked
+// against the root on each call, rejecting any that would escape it; pick this scheme for hybrid deployments that
+// serve operator-supplied content and want that hardening.
+func localFSOpener(ctx context.Context, rawPath string, config fs.Config) (fs.FileSystem, error) {
+	return localfs.NewLocalFileSystem(strings.TrimPrefix(rawPath, "localfs://"))
+}
+
+// initFileSystems opens the file system rooted at --root, plus one for every path in --file-systems, dispatching
+// each through the fs.Register registry on its "scheme://" prefix.  New backends need only an Opener registered by
+// init above; this function and checkConfig don't change when one is added.
+func initFileSystems(ctx context.Context, v *viper.Viper) (map[string]fs.FileSystem, error) {
+	rootPath := v.GetString(flagRootPath)
+	fileSystemPathsString := v.GetString(flagFileSystems)
+	fileSystemPathsSlice := []string{}
+	if len(fileSystemPathsString) > 0 {
+		err := json.Unmarshal([]byte(fileSystemPathsString), &fileSystemPathsSlice)
+		if err != nil {
+			return nil, fmt.Errorf("invalid format for file systems: %w", err)
+		}
 	}
 
 	fileSystems := map[string]fs.FileSystem{}
 
 	if len(rootPath) > 0 {
-		fileSystems[rootPath] = initFileSystem(ctx, rootPath, s3Client, maxDirectoryEntries)
+		fileSystem, err := fs.Open(ctx, rootPath, v)
+		if err != nil {
+			return nil, fmt.Errorf("error opening root file system %q: %w", rootPath, err)
+		}
+		fileSystems[rootPath] = fileSystem
 	}
 
-	if len(fileSystemPathsSlice) > 0 {
-		for _, fileSystemPath := range fileSystemPathsSlice {
-			fileSystems[fileSystemPath] = initFileSystem(ctx, fileSystemPath, s3Client, maxDirectoryEntries)
+	for _, fileSystemPath := range fileSystemPathsSlice {
+		fileSystem, err := fs.Open(ctx, fileSystemPath, v)
+		if err != nil {
+			return nil, fmt.Errorf("error opening file system %q: %w", fileSystemPath, err)
 		}
+		fileSystems[fileSystemPath] = fileSystem
 	}
 
 	return fileSystems, nil
 }
 
+// initCaching wraps every file system in fileSystems with an fs.CachingFileSystem, in place, when --cache-size is
+// greater than 0, and warms each one if --cache-warm is set.  It is a no-op when caching is disabled.
+func initCaching(ctx context.Context, v *viper.Viper, fileSystems map[string]fs.FileSystem, logger *log.SimpleLogger) error {
+	cacheSize := v.GetInt(flagCacheSize)
+	if cacheSize <= 0 {
+		return nil
+	}
+	cacheTTL, err := time.ParseDuration(v.GetString(flagCacheTTL))
+	if err != nil {
+		return fmt.Errorf("error parsing cache ttl: %w", err)
+	}
+	cacheNegativeTTL, err := time.ParseDuration(v.GetString(flagCacheNegativeTTL))
+	if err != nil {
+		return fmt.Errorf("error parsing cache negative ttl: %w", err)
+	}
+	cacheWarm := v.GetBool(flagCacheWarm)
+	cacheMaxObjectSize := v.GetInt64(flagCacheMaxObjectSize)
+	for rootPath, fileSystem := range fileSystems {
+		cachingFileSystem := fs.NewCachingFileSystem(fileSystem, cacheSize, cacheTTL, cacheNegativeTTL, cacheMaxObjectSize)
+		fileSystems[rootPath] = cachingFileSystem
+		if cacheWarm {
+			_ = logger.Log("Warming cache", map[string]interface{}{
+				"path": rootPath,
+			})
+			if err := cachingFileSystem.Warm(ctx, "/"); err != nil {
+				return fmt.Errorf("error warming cache for %q: %w", rootPath, err)
+			}
+		}
+	}
+	return nil
+}
+
 func initSites(v *viper.Viper) (map[string]string, error) {
 	sitesString := v.GetString(flagSites)
 	sitesMap := map[string]string{}
@@ -751,7 +1327,33 @@ func main() {
 		},
 	}
 
-	defaultsCommand.AddCommand(showDefaultTLSCipherSuites, showDefaultTLSCurvePreferences)
+	showDefaultACMEDirectories := &cobra.Command{
+		Use:                   `acme-directories`,
+		DisableFlagsInUseLine: true,
+		Short:                 "show well-known ACME CA directory URLs",
+		SilenceErrors:         true,
+		SilenceUsage:          true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, err := initViper(cmd)
+			if err != nil {
+				return fmt.Errorf("error initializing viper: %w", err)
+			}
+			if len(args) > 0 {
+				return cmd.Usage()
+			}
+			names := make([]string, 0, len(WellKnownACMEDirectories))
+			for name := range WellKnownACMEDirectories {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				fmt.Printf("%s\t%s\n", name, WellKnownACMEDirectories[name])
+			}
+			return nil
+		},
+	}
+
+	defaultsCommand.AddCommand(showDefaultTLSCipherSuites, showDefaultTLSCurvePreferences, showDefaultACMEDirectories)
 
 	serveCommand := &cobra.Command{
 		Use:                   `serve [flags]`,
@@ -809,12 +1411,28 @@ serve --addr :8080 --server-key-pairs '[["server.crt", "server.key"]]' --file-sy
 
 			defaultRootPath := v.GetString(flagRootPath)
 
-			maxDirectoryEntries := v.GetInt(flagMaxDirectoryEntries)
-			fileSystems, err := initFileSystems(ctx, v, maxDirectoryEntries)
+			fileSystems, err := initFileSystems(ctx, v)
 			if err != nil {
 				return fmt.Errorf("error initializing file systems: %w", err)
 			}
 
+			if err := initCaching(ctx, v, fileSystems, logger); err != nil {
+				return fmt.Errorf("error initializing cache: %w", err)
+			}
+
+			tracerProvider, err := initTracerProvider(ctx, v)
+			if err != nil {
+				return fmt.Errorf("error initializing otel tracer: %w", err)
+			}
+			if tracerProvider != nil {
+				otel.SetTracerProvider(tracerProvider)
+				_ = logger.Log("Exporting traces", map[string]interface{}{
+					"endpoint": v.GetString(flagOTelEndpoint),
+					"protocol": v.GetString(flagOTelProtocol),
+				})
+			}
+			tracer := otel.Tracer("icecube")
+
 			sites, err := initSites(v)
 			if err != nil {
 				return fmt.Errorf("error initializing sites: %w", err)
@@ -834,13 +1452,20 @@ serve --addr :8080 --server-key-pairs '[["server.crt", "server.key"]]' --file-sy
 				return fmt.Errorf("error loading server key pairs: %w", err)
 			}
 
-			if defaultServerKeyPair == nil {
+			if defaultServerKeyPair == nil && len(serverKeyPairs) > 0 {
 				// if default server key pair is nil, then set the value to the first key pair provided
 				defaultServerKeyPair = &serverKeyPairs[0]
-			} else {
+			} else if defaultServerKeyPair != nil {
 				// if default server key pair is not nil, then add to the slice of key pairs
 				serverKeyPairs = append(serverKeyPairs, *defaultServerKeyPair)
 			}
+			// defaultServerKeyPair and serverKeyPairs may both be empty here when --acme is enabled without a
+			// static fallback certificate; initTLSConfig and acmeManager.GetCertificate handle that case.
+
+			acmeManager, err := initACMEManager(v, sites)
+			if err != nil {
+				return fmt.Errorf("error initializing acme: %w", err)
+			}
 
 			tlsMinVersion := v.GetString(flagTLSMinVersion)
 
@@ -855,7 +1480,16 @@ serve --addr :8080 --server-key-pairs '[["server.crt", "server.key"]]' --file-sy
 				return fmt.Errorf("error initializing cipher suites: %w", err)
 			}
 
-			tlsConfig, err := initTLSConfig(v, defaultServerKeyPair, serverKeyPairs, tlsMinVersion, tlsMaxVersion, cipherSuites, keyLogger)
+			clientCAs, err := initClientCAs(v)
+			if err != nil {
+				return fmt.Errorf("error initializing client CAs: %w", err)
+			}
+			clientAuthzPolicy, err := initClientAuthzPolicy(v)
+			if err != nil {
+				return fmt.Errorf("error initializing client authorization policy: %w", err)
+			}
+
+			tlsConfig, err := initTLSConfig(v, defaultServerKeyPair, serverKeyPairs, tlsMinVersion, tlsMaxVersion, cipherSuites, keyLogger, acmeManager, clientCAs, ClientAuthIdentifiers[v.GetString(flagClientAuth)], clientAuthzPolicy)
 			if err != nil {
 				return fmt.Errorf("error initializing TLS config: %w", err)
 			}
@@ -868,16 +1502,37 @@ serve --addr :8080 --server-key-pairs '[["server.crt", "server.key"]]' --file-sy
 
 			var directoryTemplate template.Template
 			if len(directoryTemplatePath) > 0 {
-				t, err := template.ParseFile("index.html", directoryTemplatePath)
+				directoryTemplateFixture := map[string]interface{}{
+					"Name":             "/",
+					"DirectoryEntries": []fs.DirectoryEntry{},
+					"IcecubeVersion":   IcecubeVersion,
+				}
+				m, err := template.NewManager("index.html", directoryTemplatePath, directoryTemplateFixture, func(err error) {
+					_ = logger.Log("Error watching directory template", map[string]interface{}{
+						"path":  directoryTemplatePath,
+						"error": err.Error(),
+					})
+				})
 				if err != nil {
 					return fmt.Errorf("error parsing directory template: %w", err)
 				}
-				directoryTemplate = t
+				directoryTemplate = m
 				_ = logger.Log("Using directory template", map[string]interface{}{
 					"path": directoryTemplatePath,
 				})
 			}
 
+			cacheControl := v.GetString(flagCacheControl)
+
+			s3GatewayPrefix := v.GetString(flagS3Gateway)
+			var s3GatewayHandler http.Handler
+			if len(s3GatewayPrefix) > 0 {
+				s3GatewayHandler = server.NewS3GatewayHandler(server.NewS3Gateway(fileSystems, nil), s3GatewayPrefix)
+				_ = logger.Log("Serving S3 gateway", map[string]interface{}{
+					"prefix": s3GatewayPrefix,
+				})
+			}
+
 			httpsServer := &http.Server{
 				Addr:         listenAddress,
 				IdleTimeout:  v.GetDuration(flagTimeoutIdle),
@@ -885,11 +1540,35 @@ serve --addr :8080 --server-key-pairs '[["server.crt", "server.key"]]' --file-sy
 				WriteTimeout: v.GetDuration(flagTimeoutWrite),
 				TLSConfig:    tlsConfig,
 				ErrorLog:     log.WrapStandardLogger(logger),
-				Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				Handler: http.HandlerFunc(func(w0 http.ResponseWriter, r *http.Request) {
+					//
+					startTime := time.Now()
+					cw := &countingResponseWriter{ResponseWriter: w0, statusCode: http.StatusOK}
+					var w http.ResponseWriter = cw
+					//
+					requestContext, span := tracer.Start(r.Context(), "icecube.serve")
+					defer span.End()
 					//
 					icecubeTraceID := newTraceID()
+					if span.SpanContext().IsValid() {
+						icecubeTraceID = span.SpanContext().TraceID().String()
+					}
 					tlsServerName := r.TLS.ServerName
-					requestContext := r.Context()
+					callerIdentity := server.ExtractCallerIdentity(r.TLS)
+					certCN := ""
+					if len(r.TLS.PeerCertificates) > 0 {
+						certCN = r.TLS.PeerCertificates[0].Subject.CommonName
+					}
+					span.SetAttributes(
+						attribute.String("tls.version", getTLSVersion(r)),
+						attribute.String("tls.server_name", tlsServerName),
+						attribute.String("tls.client_cert_cn", certCN),
+					)
+					//
+					if s3GatewayHandler != nil && strings.HasPrefix(r.URL.Path, s3GatewayPrefix) {
+						s3GatewayHandler.ServeHTTP(w, r)
+						return
+					}
 					//
 					_ = logger.Log("Request", map[string]interface{}{
 						"url":              r.URL.String(),
@@ -900,10 +1579,24 @@ serve --addr :8080 --server-key-pairs '[["server.crt", "server.key"]]' --file-sy
 						"icecube_trace_id": icecubeTraceID,
 						"tls_version":      getTLSVersion(r),
 						"tls_server_name":  tlsServerName,
+						"caller_identity":  callerIdentity.String(),
 					})
 
 					// Check site
 					fileSystemPath := defaultRootPath
+					defer func() {
+						backend := "local"
+						if fs.Scheme(fileSystemPath) != "file" {
+							backend = fileSystemPath
+						}
+						server.RequestDuration.WithLabelValues(fileSystemPath, r.Method, strconv.Itoa(cw.statusCode)).Observe(time.Since(startTime).Seconds())
+						server.BytesServed.WithLabelValues(fileSystemPath).Add(float64(cw.bytesWritten))
+						span.SetAttributes(
+							attribute.String("site", fileSystemPath),
+							attribute.String("backend", backend),
+							attribute.Int64("response.bytes", cw.bytesWritten),
+						)
+					}()
 					if len(sites) > 0 {
 						str, ok := sites[tlsServerName]
 						if !ok {
@@ -936,7 +1629,19 @@ serve --addr :8080 --server-key-pairs '[["server.crt", "server.key"]]' --file-sy
 						return
 					}
 
-					fi, err := fs.Stat(requestContext, trimmedPath)
+					if clientAuthzPolicy != nil && !clientAuthzPolicy.Allowed(callerIdentity, tlsServerName, trimmedPath, r.Method) {
+						_ = logger.Log("Caller not authorized", map[string]interface{}{
+							"icecube_trace_id": icecubeTraceID,
+							"caller_identity":  callerIdentity.String(),
+							"path":             trimmedPath,
+							"method":           r.Method,
+						})
+						http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+						return
+					}
+
+					fi, cacheHit, err := statWithCacheHit(requestContext, fs, trimmedPath)
+					span.SetAttributes(attribute.Bool("cache.hit", cacheHit))
 					if err != nil {
 						if fs.IsNotExist(err) {
 							_ = logger.Log("Not found", map[string]interface{}{
@@ -1011,7 +1716,7 @@ serve --addr :8080 --server-key-pairs '[["server.crt", "server.key"]]' --file-sy
 								return
 							}
 							// Serve Rendered Directory Template
-							server.ServeContent(w, r, trimmedPath, bytes.NewReader(buf.Bytes()), fi.ModTime(), false, nil)
+							server.ServeContent(w, r, trimmedPath, bytes.NewReader(buf.Bytes()), fi.ModTime(), "", cacheControl, false, nil)
 							return
 						}
 
@@ -1043,23 +1748,40 @@ serve --addr :8080 --server-key-pairs '[["server.crt", "server.key"]]' --file-sy
 					server.ServeFile(w, r, fs, trimmedPath, fi.ModTime(), true, nil)
 				}),
 			}
+
+			if recordPath := v.GetString(flagRecordPath); len(recordPath) > 0 {
+				recorder, err := playback.NewRecorder(recordPath, 0)
+				if err != nil {
+					return fmt.Errorf("error initializing playback recorder: %w", err)
+				}
+				httpsServer.Handler = playback.RecordingHandler(httpsServer.Handler, recorder)
+				_ = logger.Log("Recording requests", map[string]interface{}{
+					"path": recordPath,
+				})
+			}
 			// If dry run, then return before starting servers.
 			if v.GetBool(flagDryRun) {
 				return nil
 			}
 			//
 			if len(redirectAddress) > 0 && len(publicLocation) > 0 {
+				var redirectHandler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					_ = logger.Log("Redirecting request", map[string]interface{}{
+						"icecube_trace_id": newTraceID(),
+						"url":              r.URL.String(),
+						"target":           publicLocation,
+					})
+					http.Redirect(w, r, publicLocation, http.StatusSeeOther)
+				})
+				if acmeManager != nil && v.GetString(flagACMEChallenge) == ACMEChallengeHTTP01 {
+					// acmeManager.HTTPHandler intercepts "/.well-known/acme-challenge/" requests for the http-01
+					// challenge and falls through to redirectHandler for everything else.
+					redirectHandler = acmeManager.HTTPHandler(redirectHandler)
+				}
 				httpServer := &http.Server{
 					Addr:     redirectAddress,
 					ErrorLog: log.WrapStandardLogger(logger),
-					Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-						_ = logger.Log("Redirecting request", map[string]interface{}{
-							"icecube_trace_id": newTraceID(),
-							"url":              r.URL.String(),
-							"target":           publicLocation,
-						})
-						http.Redirect(w, r, publicLocation, http.StatusSeeOther)
-					}),
+					Handler:  redirectHandler,
 				}
 				_ = logger.Log("Redirecting http to https", map[string]interface{}{
 					"source": redirectAddress,
@@ -1068,6 +1790,20 @@ serve --addr :8080 --server-key-pairs '[["server.crt", "server.key"]]' --file-sy
 				go func() { _ = httpServer.ListenAndServe() }()
 			}
 			//
+			if metricsAddr := v.GetString(flagMetricsAddr); len(metricsAddr) > 0 {
+				metricsMux := http.NewServeMux()
+				metricsMux.Handle("/metrics", server.MetricsHandler())
+				metricsServer := &http.Server{
+					Addr:     metricsAddr,
+					ErrorLog: log.WrapStandardLogger(logger),
+					Handler:  metricsMux,
+				}
+				_ = logger.Log("Serving metrics", map[string]interface{}{
+					"addr": metricsAddr,
+				})
+				go func() { _ = metricsServer.ListenAndServe() }()
+			}
+			//
 			_ = logger.Log("Starting server", map[string]interface{}{
 				"addr":          listenAddress,
 				"idleTimeout":   httpsServer.IdleTimeout.String(),
@@ -1081,6 +1817,60 @@ serve --addr :8080 --server-key-pairs '[["server.crt", "server.key"]]' --file-sy
 	}
 	initServeFlags(serveCommand.Flags())
 
+	playbackCommand := &cobra.Command{
+		Use:                   `playback <path>`,
+		DisableFlagsInUseLine: true,
+		Short:                 "replay a recording made with serve --record against a running icecube instance",
+		Example:               `playback --target https://localhost:8443 requests.jsonl`,
+		SilenceErrors:         true,
+		SilenceUsage:          true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			ctx := cmd.Context()
+
+			v, err := initViper(cmd)
+			if err != nil {
+				return fmt.Errorf("error initializing viper: %w", err)
+			}
+
+			if len(args) != 1 {
+				return cmd.Usage()
+			}
+
+			target := v.GetString(flagPlaybackTarget)
+			if len(target) == 0 {
+				return fmt.Errorf("target is required")
+			}
+
+			player := playback.NewPlayer(
+				target,
+				playback.WithWorkers(v.GetInt(flagPlaybackWorkers)),
+				playback.WithRampUp(v.GetDuration(flagPlaybackRampUp)),
+				playback.WithPreserveTiming(v.GetBool(flagPlaybackPreserveTiming)),
+			)
+
+			report, err := player.Play(ctx, args[0])
+			if err != nil {
+				return fmt.Errorf("error replaying recording: %w", err)
+			}
+
+			out, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return fmt.Errorf("error formatting report: %w", err)
+			}
+			fmt.Println(string(out))
+
+			if len(report.Mismatches) > 0 {
+				return fmt.Errorf("%d of %d replayed requests did not match the recording", len(report.Mismatches), report.Total)
+			}
+			return nil
+		},
+	}
+	playbackCommand.Flags().String(flagPlaybackTarget, "", "base URL of the running icecube instance to replay requests against")
+	playbackCommand.Flags().Int(flagPlaybackWorkers, 1, "number of requests replayed concurrently")
+	playbackCommand.Flags().Duration(flagPlaybackRampUp, 0, "duration over which worker startup is spread out, instead of starting all workers at once")
+	playbackCommand.Flags().Bool(flagPlaybackPreserveTiming, false, "replay requests with the same gaps between them that were recorded")
+
 	versionCommand := &cobra.Command{
 		Use:                   `version`,
 		DisableFlagsInUseLine: true,
@@ -1093,7 +1883,7 @@ serve --addr :8080 --server-key-pairs '[["server.crt", "server.key"]]' --file-sy
 		},
 	}
 
-	rootCommand.AddCommand(defaultsCommand, serveCommand, versionCommand)
+	rootCommand.AddCommand(defaultsCommand, serveCommand, playbackCommand, versionCommand)
 
 	if err := rootCommand.Execute(); err != nil {
 		_, _ = fmt.Fprintln(os.Stderr, "icecube: "+err.Error())
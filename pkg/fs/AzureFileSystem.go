@@ -0,0 +1,191 @@
+// =================================================================
+//
+// Work of the U.S. Department of Defense, Defense Digital Service.
+// Released as open source under the MIT License.  See LICENSE file.
+//
+// =================================================================
+
+package fs
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+)
+
+// AzureFileSystem is a FileSystem backed by a single Azure Blob Storage container, rooted at prefix within that
+// container.
+type AzureFileSystem struct {
+	client    *azblob.Client
+	container string
+	prefix    string
+}
+
+type AzureDirectoryEntry struct {
+	name    string
+	dir     bool
+	modTime time.Time
+	size    int64
+}
+
+func (de *AzureDirectoryEntry) IsDir() bool        { return de.dir }
+func (de *AzureDirectoryEntry) Name() string       { return de.name }
+func (de *AzureDirectoryEntry) ModTime() time.Time { return de.modTime }
+func (de *AzureDirectoryEntry) Size() int64        { return de.size }
+
+func stringVal(p *string) string {
+	if p == nil {
+		return ""
+	}
+	return *p
+}
+
+func int64Val(p *int64) int64 {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
+
+func timeVal(p *time.Time) time.Time {
+	if p == nil {
+		return time.Time{}
+	}
+	return *p
+}
+
+func (fs *AzureFileSystem) key(name string) string {
+	if len(fs.prefix) == 0 {
+		return strings.TrimPrefix(name, "/")
+	}
+	return fs.Join(fs.prefix, name)
+}
+
+func (fs *AzureFileSystem) IsNotExist(err error) bool {
+	return bloberror.HasCode(err, bloberror.BlobNotFound, bloberror.ContainerNotFound)
+}
+
+func (fs *AzureFileSystem) Join(name ...string) string {
+	return path.Join(name...)
+}
+
+// ReadDir lists the blobs and virtual directories one level under name, using "/" as the delimiter so that nested
+// "directories" are returned as a single entry rather than being walked recursively.
+func (fs *AzureFileSystem) ReadDir(ctx context.Context, name string) ([]DirectoryEntry, error) {
+	prefix := ""
+	if name != "/" {
+		prefix = fs.key(name) + "/"
+	}
+	directoryEntries := []DirectoryEntry{}
+	containerClient := fs.client.ServiceClient().NewContainerClient(fs.container)
+	pager := containerClient.NewListBlobsHierarchyPager("/", &container.ListBlobsHierarchyOptions{
+		Prefix: &prefix,
+	})
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, blobPrefix := range page.Segment.BlobPrefixes {
+			directoryEntries = append(directoryEntries, &AzureDirectoryEntry{
+				name: stringVal(blobPrefix.Name),
+				dir:  true,
+			})
+		}
+		for _, blob := range page.Segment.BlobItems {
+			directoryEntries = append(directoryEntries, &AzureDirectoryEntry{
+				name:    stringVal(blob.Name),
+				dir:     int64Val(blob.Properties.ContentLength) == 0,
+				modTime: timeVal(blob.Properties.LastModified),
+				size:    int64Val(blob.Properties.ContentLength),
+			})
+		}
+	}
+	return directoryEntries, nil
+}
+
+func (fs *AzureFileSystem) Size(ctx context.Context, name string) (int64, error) {
+	fi, err := fs.Stat(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	return fi.Size(), nil
+}
+
+func (fs *AzureFileSystem) Stat(ctx context.Context, name string) (FileInfo, error) {
+	if name == "/" {
+		return NewFileInfo(name, time.Time{}, true, int64(0)), nil
+	}
+
+	directoryEntries, err := fs.ReadDir(ctx, name)
+	if err == nil && len(directoryEntries) > 0 {
+		return NewFileInfo(name, time.Time{}, true, int64(0)), nil
+	}
+
+	props, err := fs.client.ServiceClient().NewContainerClient(fs.container).NewBlobClient(fs.key(name)).GetProperties(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	fi := NewFileInfo(name, timeVal(props.LastModified), false, int64Val(props.ContentLength))
+	etag := ""
+	if props.ETag != nil {
+		etag = string(*props.ETag)
+	}
+	var result FileInfo = &azureFileInfoWithETag{FileInfo: *fi, etag: etag}
+	return result, nil
+}
+
+// azureFileInfoWithETag decorates a FileInfo with the entity tag Azure returns from GetBlobProperties, so
+// server.ServeContent can support conditional requests without re-fetching the blob.
+type azureFileInfoWithETag struct {
+	FileInfo
+	etag string
+}
+
+func (fi *azureFileInfoWithETag) ETag() string {
+	return fi.etag
+}
+
+func (fs *AzureFileSystem) Open(ctx context.Context, name string) (io.ReadSeeker, error) {
+	fi, err := fs.Stat(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	rs := NewReadSeeker(
+		0,
+		fi.Size(),
+		func(offset int64, p []byte) (int, error) {
+			count := int64(len(p))
+			resp, err := fs.client.DownloadStream(ctx, fs.container, fs.key(name), &azblob.DownloadStreamOptions{
+				Range: azblob.HTTPRange{Offset: offset, Count: count},
+			})
+			if err != nil {
+				return 0, err
+			}
+			defer resp.Body.Close()
+			buf := &bytes.Buffer{}
+			if _, err := io.Copy(buf, resp.Body); err != nil {
+				return 0, err
+			}
+			return copy(p, buf.Bytes()), nil
+		},
+	)
+	return rs, nil
+}
+
+// NewAzureFileSystem returns an AzureFileSystem that reads blobs under prefix in containerName, using client to
+// talk to Azure Blob Storage.
+func NewAzureFileSystem(client *azblob.Client, containerName string, prefix string) *AzureFileSystem {
+	return &AzureFileSystem{
+		client:    client,
+		container: containerName,
+		prefix:    prefix,
+	}
+}
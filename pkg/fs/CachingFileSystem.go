@@ -0,0 +1,225 @@
+// =================================================================
+//
+// Work of the U.S. Department of Defense, Defense Digital Service.
+// Released as open source under the MIT License.  See LICENSE file.
+//
+// =================================================================
+
+package fs
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"sync"
+	"time"
+)
+
+// cacheEntry is the cached Stat, and optionally Open, result for a single path.
+type cacheEntry struct {
+	key      string
+	cachedAt time.Time
+	fi       FileInfo
+	content  []byte
+	err      error
+}
+
+func (entry *cacheEntry) expired(now time.Time, ttl time.Duration, negativeTTL time.Duration) bool {
+	if entry.err != nil {
+		return now.Sub(entry.cachedAt) >= negativeTTL
+	}
+	return now.Sub(entry.cachedAt) >= ttl
+}
+
+// cachingFileInfoWithETag decorates a FileInfo with an ETag computed by CachingFileSystem, for backends (Vault, a
+// local disk) whose own Stat doesn't already implement FileInfoWithETag.
+type cachingFileInfoWithETag struct {
+	FileInfo
+	etag string
+}
+
+func (fi *cachingFileInfoWithETag) ETag() string {
+	return fi.etag
+}
+
+// sha256ETag returns a strong ETag (RFC 7232) for content, for backends that don't hand back an ETag of their own.
+func sha256ETag(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "\"" + hex.EncodeToString(sum[:]) + "\""
+}
+
+// DefaultMaxCachedObjectSize is the largest object content CachingFileSystem will buffer into the cache, used when
+// NewCachingFileSystem is given maxCachedObjectSize <= 0.  Objects larger than this are served straight from the
+// backend's own ReadSeeker on every Open, uncached, so a handful of large objects can't exhaust the cache's memory
+// budget the way buffering every object regardless of size would.
+const DefaultMaxCachedObjectSize = 8 * 1024 * 1024 // 8 MiB
+
+// CachingFileSystem wraps a FileSystem with an in-memory, size-bounded LRU cache of Stat and Open results, keyed by
+// path.  It exists to keep repeated requests for the same object from re-fetching it from a slow or metered backend
+// (S3, GCS, Azure Blob, Vault) on every request: once an object's content has been read once, Opens within ttl are
+// served from memory, and the FileInfo returned by Stat carries a strong ETag (the backend's own ETag, for FileInfo
+// that already implements FileInfoWithETag, otherwise a sha256 of the cached content) so server.ServeContent's
+// conditional-request and Range handling — both backed by the standard library's http.ServeContent — are satisfied
+// without touching the backend at all. A Stat that fails with IsNotExist is cached too, for negativeTTL, so a flood
+// of requests for a missing object doesn't turn into a flood of backend lookups.  ReadDir and Size are not cached:
+// directory listings change shape too often for a simple TTL to be worth the staleness risk, and Size is cheap
+// relative to Stat/Open once those are cached.  Content is only cached for objects up to maxCachedObjectSize;
+// anything larger is served directly against the backend's own ReadSeeker on every Open, uncached, so content
+// caching can't be used to exhaust memory against a backend holding large objects.
+type CachingFileSystem struct {
+	FileSystem
+	size                int
+	ttl                 time.Duration
+	negativeTTL         time.Duration
+	maxCachedObjectSize int64
+
+	mu      sync.Mutex
+	entries map[string]*list.Element // path -> element whose Value is *cacheEntry
+	order   *list.List               // front = most recently used
+}
+
+// NewCachingFileSystem returns a CachingFileSystem wrapping base, holding up to size entries.  A positive Stat or
+// Open result is trusted for ttl; a "not found" Stat result is trusted for negativeTTL.  size <= 0 disables the
+// cache, so a CachingFileSystem can be constructed unconditionally and simply pass every call through to base.
+// maxCachedObjectSize <= 0 uses DefaultMaxCachedObjectSize; objects larger than it are never buffered into the
+// cache, regardless of size.
+func NewCachingFileSystem(base FileSystem, size int, ttl time.Duration, negativeTTL time.Duration, maxCachedObjectSize int64) *CachingFileSystem {
+	if maxCachedObjectSize <= 0 {
+		maxCachedObjectSize = DefaultMaxCachedObjectSize
+	}
+	return &CachingFileSystem{
+		FileSystem:          base,
+		size:                size,
+		ttl:                 ttl,
+		negativeTTL:         negativeTTL,
+		maxCachedObjectSize: maxCachedObjectSize,
+		entries:             map[string]*list.Element{},
+		order:               list.New(),
+	}
+}
+
+func (cfs *CachingFileSystem) lookup(name string) (*cacheEntry, bool) {
+	cfs.mu.Lock()
+	defer cfs.mu.Unlock()
+	elem, ok := cfs.entries[name]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if entry.expired(time.Now(), cfs.ttl, cfs.negativeTTL) {
+		cfs.order.Remove(elem)
+		delete(cfs.entries, name)
+		return nil, false
+	}
+	cfs.order.MoveToFront(elem)
+	return entry, true
+}
+
+func (cfs *CachingFileSystem) store(entry *cacheEntry) {
+	cfs.mu.Lock()
+	defer cfs.mu.Unlock()
+	if elem, ok := cfs.entries[entry.key]; ok {
+		elem.Value = entry
+		cfs.order.MoveToFront(elem)
+		return
+	}
+	cfs.entries[entry.key] = cfs.order.PushFront(entry)
+	for cfs.order.Len() > cfs.size {
+		oldest := cfs.order.Back()
+		delete(cfs.entries, oldest.Value.(*cacheEntry).key)
+		cfs.order.Remove(oldest)
+	}
+}
+
+func (cfs *CachingFileSystem) statWithHit(ctx context.Context, name string) (FileInfo, bool, error) {
+	if cfs.size <= 0 {
+		fi, err := cfs.FileSystem.Stat(ctx, name)
+		return fi, false, err
+	}
+	if entry, ok := cfs.lookup(name); ok {
+		return entry.fi, true, entry.err
+	}
+	fi, err := cfs.FileSystem.Stat(ctx, name)
+	if err != nil && !cfs.IsNotExist(err) {
+		return nil, false, err
+	}
+	cfs.store(&cacheEntry{key: name, cachedAt: time.Now(), fi: fi, err: err})
+	return fi, false, err
+}
+
+func (cfs *CachingFileSystem) Stat(ctx context.Context, name string) (FileInfo, error) {
+	fi, _, err := cfs.statWithHit(ctx, name)
+	return fi, err
+}
+
+// StatCached behaves like Stat, but also reports whether the FileInfo came from cache, for callers (e.g. request
+// tracing) that want to record a cache hit/miss without duplicating CachingFileSystem's own bookkeeping.
+func (cfs *CachingFileSystem) StatCached(ctx context.Context, name string) (FileInfo, bool, error) {
+	return cfs.statWithHit(ctx, name)
+}
+
+func (cfs *CachingFileSystem) Open(ctx context.Context, name string) (io.ReadSeeker, error) {
+	if cfs.size <= 0 {
+		return cfs.FileSystem.Open(ctx, name)
+	}
+	if entry, ok := cfs.lookup(name); ok && entry.content != nil {
+		return bytes.NewReader(entry.content), nil
+	}
+
+	// an object whose size exceeds maxCachedObjectSize is served straight from the backend, on every
+	// Open, rather than buffered into the cache: a handful of large objects could otherwise exhaust the cache's
+	// memory budget, which --cache-size (an entry count, not a byte budget) does nothing to bound.
+	if fi, statErr := cfs.Stat(ctx, name); statErr == nil && fi.Size() > cfs.maxCachedObjectSize {
+		return cfs.FileSystem.Open(ctx, name)
+	}
+
+	rs, err := cfs.FileSystem.Open(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	content, err := io.ReadAll(rs)
+	if err != nil {
+		return nil, err
+	}
+
+	fi, statErr := cfs.Stat(ctx, name)
+	if statErr != nil {
+		return bytes.NewReader(content), nil
+	}
+	if _, ok := fi.(FileInfoWithETag); !ok {
+		fi = &cachingFileInfoWithETag{FileInfo: fi, etag: sha256ETag(content)}
+	}
+	cfs.store(&cacheEntry{key: name, cachedAt: time.Now(), fi: fi, content: content})
+
+	return bytes.NewReader(content), nil
+}
+
+// Warm populates the cache by recursively Stat-ing and Opening every file under root, so the first real requests
+// after startup are served from memory instead of paying a cold-cache fetch.  It is meant for --cache-warm; errors
+// reading any single file are returned immediately rather than skipped, since a warm that silently misses files
+// would be worse than no warm at all.
+func (cfs *CachingFileSystem) Warm(ctx context.Context, root string) error {
+	directoryEntries, err := cfs.ReadDir(ctx, root)
+	if err != nil {
+		return err
+	}
+	for _, de := range directoryEntries {
+		childPath := cfs.Join(root, de.Name())
+		if de.IsDir() {
+			if err := cfs.Warm(ctx, childPath); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := cfs.Stat(ctx, childPath); err != nil {
+			return err
+		}
+		if _, err := cfs.Open(ctx, childPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
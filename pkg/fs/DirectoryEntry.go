@@ -14,3 +14,12 @@ type DirectoryEntry interface {
 	IsDir() bool
 	ModTime() time.Time
 }
+
+// DirectoryEntryWithSize is implemented by DirectoryEntry values that already know their own size from the
+// listing that produced them (S3DirectoryEntry, GCSDirectoryEntry, LocalDirectoryEntry, and memDirectoryEntry all
+// do). It is optional, following the same pattern as FileInfoWithETag: a caller that wants to sort or display by
+// size type-asserts for it rather than requiring every DirectoryEntry to carry one.
+type DirectoryEntryWithSize interface {
+	DirectoryEntry
+	Size() int64
+}
@@ -17,3 +17,49 @@ type FileInfo interface {
 	ModTime() time.Time
 	Size() int64
 }
+
+// fileInfo is the basic FileInfo every backend in this package builds on.  Its methods have value receivers so
+// that both fileInfo and *fileInfo satisfy FileInfo: callers that want a richer FileInfo (an ETag, metadata, a
+// version ID) dereference the *fileInfo NewFileInfo returns and embed the resulting value in their own wrapper
+// struct, rather than reimplementing the four base methods.
+type fileInfo struct {
+	name    string
+	modTime time.Time
+	isDir   bool
+	size    int64
+}
+
+func (fi fileInfo) IsDir() bool        { return fi.isDir }
+func (fi fileInfo) Name() string       { return fi.name }
+func (fi fileInfo) ModTime() time.Time { return fi.modTime }
+func (fi fileInfo) Size() int64        { return fi.size }
+
+// NewFileInfo returns a basic FileInfo describing name: whether it's a directory, when it was last modified, and
+// its size (0 for directories).  Backends that can supply more (an ETag, user metadata, a version ID) wrap the
+// result in their own FileInfoWithETag / FileInfoWithMetadata implementation rather than reimplementing FileInfo.
+func NewFileInfo(name string, modTime time.Time, isDir bool, size int64) *fileInfo {
+	return &fileInfo{
+		name:    name,
+		modTime: modTime,
+		isDir:   isDir,
+		size:    size,
+	}
+}
+
+// FileInfoWithETag is implemented by FileInfo values that can supply a strong entity tag for conditional requests.
+// It is optional: backends that can produce one cheaply (S3's HeadObject ETag, a hash of a local file's path, size,
+// and mtime) implement it, and callers type-assert for it rather than requiring it of every FileInfo.
+type FileInfoWithETag interface {
+	FileInfo
+	ETag() string
+}
+
+// FileInfoWithMetadata is implemented by FileInfo values that can supply arbitrary per-object metadata (S3
+// user-defined "x-amz-meta-*" tags, and standard headers such as Content-Type, Content-Encoding, Cache-Control,
+// and ETag) without a second round trip to the backend.  It is optional, following the same pattern as
+// FileInfoWithETag: a backend that already has this metadata on hand once it has Stat'd or HeadObject'd an object
+// implements it, and callers type-assert for it.
+type FileInfoWithMetadata interface {
+	FileInfo
+	Metadata() map[string]string
+}
@@ -20,3 +20,11 @@ type FileSystem interface {
 	Stat(ctx context.Context, name string) (FileInfo, error)
 	Open(ctx context.Context, name string) (io.ReadSeeker, error)
 }
+
+// PagedFileSystem is implemented by file systems that can return a directory listing one page at a time.
+// token is opaque to the caller: pass "" to start at the beginning, and pass the returned nextToken back in to resume.
+// nextToken is "" once the listing is exhausted.
+type PagedFileSystem interface {
+	FileSystem
+	ReadDirPage(ctx context.Context, name string, token string, limit int) (entries []DirectoryEntry, nextToken string, err error)
+}
@@ -0,0 +1,166 @@
+// =================================================================
+//
+// Work of the U.S. Department of Defense, Defense Digital Service.
+// Released as open source under the MIT License.  See LICENSE file.
+//
+// =================================================================
+
+package fs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+)
+
+// GCSFileSystem is a FileSystem backed by a single Google Cloud Storage bucket, rooted at prefix within that
+// bucket.  Unlike S3FileSystem, ReadDir is not paginated: the storage.Client iterator already pages over the
+// listing internally, so there is no cursor worth exposing to callers.
+type GCSFileSystem struct {
+	bucket     *storage.BucketHandle
+	bucketName string
+	prefix     string
+}
+
+type GCSDirectoryEntry struct {
+	name    string
+	dir     bool
+	modTime time.Time
+	size    int64
+}
+
+func (de *GCSDirectoryEntry) IsDir() bool        { return de.dir }
+func (de *GCSDirectoryEntry) Name() string       { return de.name }
+func (de *GCSDirectoryEntry) ModTime() time.Time { return de.modTime }
+func (de *GCSDirectoryEntry) Size() int64        { return de.size }
+
+func (fs *GCSFileSystem) key(name string) string {
+	if len(fs.prefix) == 0 {
+		return strings.TrimPrefix(name, "/")
+	}
+	return fs.Join(fs.prefix, name)
+}
+
+func (fs *GCSFileSystem) IsNotExist(err error) bool {
+	return errors.Is(err, storage.ErrObjectNotExist) || errors.Is(err, storage.ErrBucketNotExist)
+}
+
+func (fs *GCSFileSystem) Join(name ...string) string {
+	return path.Join(name...)
+}
+
+// ReadDir lists the objects and common prefixes one level under name, using "/" as the delimiter so that nested
+// "directories" are returned as a single entry rather than being walked recursively.
+func (fs *GCSFileSystem) ReadDir(ctx context.Context, name string) ([]DirectoryEntry, error) {
+	query := &storage.Query{Delimiter: "/"}
+	if name != "/" {
+		query.Prefix = fs.key(name) + "/"
+	}
+	it := fs.bucket.Objects(ctx, query)
+	directoryEntries := []DirectoryEntry{}
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(attrs.Prefix) > 0 {
+			directoryEntries = append(directoryEntries, &GCSDirectoryEntry{
+				name: attrs.Prefix,
+				dir:  true,
+			})
+			continue
+		}
+		directoryEntries = append(directoryEntries, &GCSDirectoryEntry{
+			name:    attrs.Name,
+			dir:     attrs.Size == 0,
+			modTime: attrs.Updated,
+			size:    attrs.Size,
+		})
+	}
+	return directoryEntries, nil
+}
+
+func (fs *GCSFileSystem) Size(ctx context.Context, name string) (int64, error) {
+	fi, err := fs.Stat(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	return fi.Size(), nil
+}
+
+func (fs *GCSFileSystem) Stat(ctx context.Context, name string) (FileInfo, error) {
+	if name == "/" {
+		attrs, err := fs.bucket.Attrs(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return NewFileInfo(name, attrs.Created, true, int64(0)), nil
+	}
+
+	directoryEntries, err := fs.ReadDir(ctx, name)
+	if err == nil && len(directoryEntries) > 0 {
+		return NewFileInfo(name, time.Time{}, true, int64(0)), nil
+	}
+
+	attrs, err := fs.bucket.Object(fs.key(name)).Attrs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	fi := NewFileInfo(name, attrs.Updated, false, attrs.Size)
+	var result FileInfo = &gcsFileInfoWithETag{FileInfo: *fi, etag: attrs.Etag}
+	return result, nil
+}
+
+// gcsFileInfoWithETag decorates a FileInfo with the entity tag GCS returns in object Attrs, so
+// server.ServeContent can support conditional requests without re-fetching the object.
+type gcsFileInfoWithETag struct {
+	FileInfo
+	etag string
+}
+
+func (fi *gcsFileInfoWithETag) ETag() string {
+	return fi.etag
+}
+
+func (fs *GCSFileSystem) Open(ctx context.Context, name string) (io.ReadSeeker, error) {
+	fi, err := fs.Stat(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	rs := NewReadSeeker(
+		0,
+		fi.Size(),
+		func(offset int64, p []byte) (int, error) {
+			r, err := fs.bucket.Object(fs.key(name)).NewRangeReader(ctx, offset, int64(len(p)))
+			if err != nil {
+				return 0, err
+			}
+			defer r.Close()
+			n, err := io.ReadFull(r, p)
+			if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+				return n, err
+			}
+			return n, nil
+		},
+	)
+	return rs, nil
+}
+
+// NewGCSFileSystem returns a GCSFileSystem that reads objects under prefix in bucketName, using client to talk to
+// Google Cloud Storage.
+func NewGCSFileSystem(client *storage.Client, bucketName string, prefix string) *GCSFileSystem {
+	return &GCSFileSystem{
+		bucket:     client.Bucket(bucketName),
+		bucketName: bucketName,
+		prefix:     prefix,
+	}
+}
@@ -9,18 +9,22 @@ package fs
 
 import (
 	"context"
+	"crypto/sha256"
+	"fmt"
 	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/spf13/afero"
 )
 
 type LocalFileSystem struct {
-	fs   afero.Fs
-	iofs afero.IOFS
+	fs      afero.Fs
+	iofs    afero.IOFS
+	writeFS afero.Fs
 }
 
 type LocalDirectoryEntry struct {
@@ -71,12 +75,78 @@ func (fs *LocalFileSystem) ReadDir(ctx context.Context, name string) ([]Director
 	return directoryEntries, nil
 }
 
-func (fs *LocalFileSystem) Stat(ctx context.Context, name string) (*FileInfo, error) {
+// ReadDirPage returns a single page of the directory listing for name.  The local file system has no native listing
+// cursor, so token encodes the offset into the listing returned by ReadDir; pass the returned nextToken back in to
+// resume.
+func (fs *LocalFileSystem) ReadDirPage(ctx context.Context, name string, token string, limit int) ([]DirectoryEntry, string, error) {
+	offset := 0
+	if token != "" {
+		parsedOffset, err := strconv.Atoi(token)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid page token %q: %w", token, err)
+		}
+		offset = parsedOffset
+	}
+
+	directoryEntries, err := fs.ReadDir(ctx, name)
+	if err != nil {
+		return nil, "", err
+	}
+	if offset >= len(directoryEntries) {
+		return []DirectoryEntry{}, "", nil
+	}
+
+	end := len(directoryEntries)
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+
+	nextToken := ""
+	if end < len(directoryEntries) {
+		nextToken = strconv.Itoa(end)
+	}
+
+	return directoryEntries[offset:end], nextToken, nil
+}
+
+func (fs *LocalFileSystem) Stat(ctx context.Context, name string) (FileInfo, error) {
 	fi, err := fs.fs.Stat(name)
 	if err != nil {
 		return nil, err
 	}
-	return NewFileInfo(fi.Name(), fi.ModTime(), fi.IsDir(), fi.Size()), nil
+	baseInfo := NewFileInfo(fi.Name(), fi.ModTime(), fi.IsDir(), fi.Size())
+	if fi.IsDir() {
+		return baseInfo, nil
+	}
+	return &localFileInfoWithETag{FileInfo: *baseInfo, etag: localETag(name, fi.Size(), fi.ModTime())}, nil
+}
+
+// Size returns the size in bytes of the file at name.
+func (fs *LocalFileSystem) Size(ctx context.Context, name string) (int64, error) {
+	fi, err := fs.Stat(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	return fi.Size(), nil
+}
+
+// localFileInfoWithETag decorates a FileInfo with a strong ETag derived from the file's path, size, and mtime, so
+// server.ServeContent can support conditional requests without reading the file's content.
+type localFileInfoWithETag struct {
+	FileInfo
+	etag string
+}
+
+func (fi *localFileInfoWithETag) ETag() string {
+	return fi.etag
+}
+
+// localETag derives a strong entity tag from name, size, and modTime, avoiding a full content read on every Stat.
+func localETag(name string, size int64, modTime time.Time) string {
+	h := sha256.New()
+	_, _ = io.WriteString(h, name)
+	_, _ = fmt.Fprintf(h, "%d:%d", size, modTime.UnixNano())
+	return fmt.Sprintf(`"%x"`, h.Sum(nil))
 }
 
 func (fs *LocalFileSystem) Open(ctx context.Context, name string) (io.ReadSeeker, error) {
@@ -87,10 +157,32 @@ func (fs *LocalFileSystem) Open(ctx context.Context, name string) (io.ReadSeeker
 	return f, nil
 }
 
+// Create creates (or truncates) the file at name for writing, making LocalFileSystem satisfy WritableFileSystem.
+// Unlike the read path, Create is backed by a writable afero.Fs rooted at the same path, since the default
+// LocalFileSystem wraps a read-only afero.Fs.
+func (fs *LocalFileSystem) Create(ctx context.Context, name string) (io.WriteCloser, error) {
+	f, err := fs.writeFS.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Remove removes the file at name.
+func (fs *LocalFileSystem) Remove(ctx context.Context, name string) error {
+	return fs.writeFS.Remove(name)
+}
+
+// MkdirAll creates name, along with any necessary parents, with the given permissions.
+func (fs *LocalFileSystem) MkdirAll(ctx context.Context, name string, perm os.FileMode) error {
+	return fs.writeFS.MkdirAll(name, perm)
+}
+
 func NewLocalFileSystem(rootPath string) *LocalFileSystem {
 	fs := afero.NewBasePathFs(afero.NewReadOnlyFs(afero.NewOsFs()), rootPath)
 	return &LocalFileSystem{
-		fs:   fs,
-		iofs: afero.NewIOFS(fs),
+		fs:      fs,
+		iofs:    afero.NewIOFS(fs),
+		writeFS: afero.NewBasePathFs(afero.NewOsFs(), rootPath),
 	}
 }
@@ -0,0 +1,196 @@
+// =================================================================
+//
+// Work of the U.S. Department of Defense, Defense Digital Service.
+// Released as open source under the MIT License.  See LICENSE file.
+//
+// =================================================================
+
+package fs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// memObject is a single in-memory file's content and metadata.
+type memObject struct {
+	content []byte
+	modTime time.Time
+}
+
+// MemFileSystem is an in-memory FileSystem, registered under the "mem" scheme.  It exists for tests and other
+// short-lived processes that want FileSystem's behavior without standing up a real backend, and it satisfies
+// WritableFileSystem so it can also stand in for a writable backend in those same tests.  Content is held only in
+// process memory: a MemFileSystem opened from "mem://anything" starts empty, and nothing is persisted once the
+// process exits.
+type MemFileSystem struct {
+	mu      sync.RWMutex
+	objects map[string]*memObject
+}
+
+type memDirectoryEntry struct {
+	name    string
+	dir     bool
+	modTime time.Time
+	size    int64
+}
+
+func (de *memDirectoryEntry) IsDir() bool        { return de.dir }
+func (de *memDirectoryEntry) Name() string       { return de.name }
+func (de *memDirectoryEntry) ModTime() time.Time { return de.modTime }
+func (de *memDirectoryEntry) Size() int64        { return de.size }
+
+func (fs *MemFileSystem) key(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+func (fs *MemFileSystem) IsNotExist(err error) bool {
+	return errors.Is(err, os.ErrNotExist)
+}
+
+func (fs *MemFileSystem) Join(name ...string) string {
+	return path.Join(name...)
+}
+
+// ReadDir lists the objects and common prefixes one level under name, keyed the same way object keys are joined
+// elsewhere in pkg/fs, so a MemFileSystem behaves like an object store (S3, GCS) rather than a real directory tree.
+func (fs *MemFileSystem) ReadDir(ctx context.Context, name string) ([]DirectoryEntry, error) {
+	prefix := fs.key(name)
+	if len(prefix) > 0 {
+		prefix += "/"
+	}
+
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	seenDirs := map[string]bool{}
+	directoryEntries := []DirectoryEntry{}
+	for key, obj := range fs.objects {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		rest := key[len(prefix):]
+		if rest == "" {
+			continue
+		}
+		if i := strings.Index(rest, "/"); i >= 0 {
+			child := rest[:i]
+			if !seenDirs[child] {
+				seenDirs[child] = true
+				directoryEntries = append(directoryEntries, &memDirectoryEntry{name: child, dir: true})
+			}
+			continue
+		}
+		directoryEntries = append(directoryEntries, &memDirectoryEntry{name: rest, dir: false, modTime: obj.modTime, size: int64(len(obj.content))})
+	}
+	sort.Slice(directoryEntries, func(i, j int) bool { return directoryEntries[i].Name() < directoryEntries[j].Name() })
+	return directoryEntries, nil
+}
+
+func (fs *MemFileSystem) Size(ctx context.Context, name string) (int64, error) {
+	fi, err := fs.Stat(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	return fi.Size(), nil
+}
+
+func (fs *MemFileSystem) Stat(ctx context.Context, name string) (FileInfo, error) {
+	key := fs.key(name)
+
+	fs.mu.RLock()
+	obj, ok := fs.objects[key]
+	fs.mu.RUnlock()
+	if ok {
+		fi := NewFileInfo(path.Base(key), obj.modTime, false, int64(len(obj.content)))
+		var result FileInfo = &memFileInfoWithETag{FileInfo: *fi, etag: sha256ETag(obj.content)}
+		return result, nil
+	}
+
+	directoryEntries, err := fs.ReadDir(ctx, name)
+	if err == nil && len(directoryEntries) > 0 {
+		return NewFileInfo(path.Base(key), time.Time{}, true, int64(0)), nil
+	}
+
+	return nil, os.ErrNotExist
+}
+
+// memFileInfoWithETag decorates a FileInfo with a sha256 ETag of the in-memory content, the same convention
+// CachingFileSystem falls back to for backends that don't hand back an ETag of their own.
+type memFileInfoWithETag struct {
+	FileInfo
+	etag string
+}
+
+func (fi *memFileInfoWithETag) ETag() string {
+	return fi.etag
+}
+
+func (fs *MemFileSystem) Open(ctx context.Context, name string) (io.ReadSeeker, error) {
+	key := fs.key(name)
+
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+	obj, ok := fs.objects[key]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return bytes.NewReader(obj.content), nil
+}
+
+// memWriter buffers writes in memory, committing them to the owning MemFileSystem only on Close, matching the
+// write-then-commit-on-Close shape of S3Writer and the other Create implementations in this package.
+type memWriter struct {
+	fs  *MemFileSystem
+	key string
+	buf bytes.Buffer
+}
+
+func (w *memWriter) Write(p []byte) (int, error) {
+	return w.buf.Write(p)
+}
+
+func (w *memWriter) Close() error {
+	w.fs.mu.Lock()
+	defer w.fs.mu.Unlock()
+	w.fs.objects[w.key] = &memObject{content: append([]byte(nil), w.buf.Bytes()...), modTime: time.Now()}
+	return nil
+}
+
+// Create returns a writer that replaces the content at name when closed, making MemFileSystem satisfy
+// WritableFileSystem.
+func (fs *MemFileSystem) Create(ctx context.Context, name string) (io.WriteCloser, error) {
+	return &memWriter{fs: fs, key: fs.key(name)}, nil
+}
+
+// Remove deletes the object at name.
+func (fs *MemFileSystem) Remove(ctx context.Context, name string) error {
+	key := fs.key(name)
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if _, ok := fs.objects[key]; !ok {
+		return os.ErrNotExist
+	}
+	delete(fs.objects, key)
+	return nil
+}
+
+// MkdirAll is a no-op, matching S3FileSystem: MemFileSystem has no directories of its own, objects with a common
+// key prefix already behave as one.
+func (fs *MemFileSystem) MkdirAll(ctx context.Context, name string, perm os.FileMode) error {
+	return nil
+}
+
+// NewMemFileSystem returns an empty MemFileSystem.
+func NewMemFileSystem() *MemFileSystem {
+	return &MemFileSystem{objects: map[string]*memObject{}}
+}
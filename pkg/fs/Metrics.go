@@ -0,0 +1,22 @@
+// =================================================================
+//
+// Work of the U.S. Department of Defense, Defense Digital Service.
+// Released as open source under the MIT License.  See LICENSE file.
+//
+// =================================================================
+
+package fs
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// S3BackendDuration records the latency of individual calls S3FileSystem makes to an S3-compatible backend, labeled
+// by bucket and the S3 operation (e.g. "HeadObject", "GetObject", "ListObjectsV2"), so a slow or throttling bucket
+// can be told apart from a slow network path or a slow client.
+var S3BackendDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "icecube_s3_backend_duration_seconds",
+	Help:    "Duration of calls S3FileSystem makes to an S3-compatible backend, in seconds.",
+	Buckets: prometheus.DefBuckets,
+}, []string{"bucket", "operation"})
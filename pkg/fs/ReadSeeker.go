@@ -0,0 +1,263 @@
+// =================================================================
+//
+// Work of the U.S. Department of Defense, Defense Digital Service.
+// Released as open source under the MIT License.  See LICENSE file.
+//
+// =================================================================
+
+package fs
+
+import (
+	"fmt"
+	"io"
+)
+
+// DefaultReadAheadSize is the default size of the window NewReadSeeker fetches per ranged read, when the caller's
+// own read size is smaller.
+const DefaultReadAheadSize = 1 * 1024 * 1024 // 1 MiB
+
+// rangeFetch reads the byte range [offset, offset+len(p)) of the backing object into p, returning the number of
+// bytes read.
+type rangeFetch func(offset int64, p []byte) (int, error)
+
+// ReadSeekerOption configures a ReadSeeker built by NewReadSeeker.  The defaults (no prefetch, a single in-flight
+// fetch, DefaultReadAheadSize window) reproduce ReadSeeker's original behavior, so existing callers that pass no
+// options are unaffected.
+type ReadSeekerOption func(*ReadSeeker)
+
+// WithReadAheadSize overrides the size of the window fetched per ranged read (DefaultReadAheadSize if unset or <=
+// 0).
+func WithReadAheadSize(size int64) ReadSeekerOption {
+	return func(rs *ReadSeeker) {
+		if size > 0 {
+			rs.readAhead = size
+		}
+	}
+}
+
+// WithMaxConcurrentRanges bounds the number of in-flight ranged fetches, including background prefetches and
+// ReadAt calls (1 if n <= 0).
+func WithMaxConcurrentRanges(n int) ReadSeekerOption {
+	return func(rs *ReadSeeker) {
+		if n <= 0 {
+			n = 1
+		}
+		rs.sem = make(chan struct{}, n)
+	}
+}
+
+// WithPrefetchChunks enables background prefetching and sets the number of windows kept pipelined ahead of the
+// read cursor (1 if n <= 0 once prefetching is enabled).
+func WithPrefetchChunks(n int) ReadSeekerOption {
+	return func(rs *ReadSeeker) {
+		if n <= 0 {
+			n = 1
+		}
+		rs.prefetch = true
+		rs.prefetchChunks = n
+	}
+}
+
+// ReadSeeker is an io.ReadSeeker and io.ReaderAt over content fetched in byte ranges by read, backing Open for
+// every FileSystem in this package whose objects live behind a ranged-read API (S3, GCS, Azure Blob, Vault). It
+// keeps a single in-memory window [bufOffset, bufOffset+len(buf)): Read is served from that window when the
+// requested offset falls inside it, and otherwise issues one call to read sized to at least readAhead (clamped to
+// the object's size), so that the sequential reads http.ServeContent issues while streaming a response coalesce
+// into a handful of ranged fetches instead of one per Read. Seek only updates the virtual cursor; it never calls
+// read. ReadAt bypasses the window and any prefetch queue entirely, issuing an independent ranged fetch per call,
+// so concurrent callers (e.g. http.ServeContent serving a multipart range request) don't contend with the
+// sequential cursor.
+type ReadSeeker struct {
+	read      rangeFetch
+	size      int64
+	readAhead int64
+
+	prefetch       bool
+	prefetchChunks int
+	sem            chan struct{} // bounds concurrent range fetches, including background prefetch and ReadAt
+
+	offset    int64 // virtual cursor
+	bufOffset int64 // start of the current window, -1 if empty
+	buf       []byte
+
+	prefetchQueue []chan prefetchResult // in-flight/completed prefetches, in window order starting after buf
+}
+
+type prefetchResult struct {
+	offset int64
+	buf    []byte
+	err    error
+}
+
+func (rs *ReadSeeker) withinBuffer(offset int64) bool {
+	return rs.bufOffset != -1 && offset >= rs.bufOffset && offset < rs.bufOffset+int64(len(rs.buf))
+}
+
+// fill replaces the current window with one starting at offset, sized to at least minLen (clamped to readAhead's
+// larger value and to the remaining object size).
+func (rs *ReadSeeker) fill(offset int64, minLen int) error {
+	if rs.consumePrefetch(offset) {
+		return nil
+	}
+	windowLen := rs.readAhead
+	if int64(minLen) > windowLen {
+		windowLen = int64(minLen)
+	}
+	if offset+windowLen > rs.size {
+		windowLen = rs.size - offset
+	}
+	buf := make([]byte, windowLen)
+	rs.sem <- struct{}{}
+	n, err := rs.read(offset, buf)
+	<-rs.sem
+	if err != nil && err != io.EOF {
+		return err
+	}
+	rs.bufOffset = offset
+	rs.buf = buf[:n]
+	return nil
+}
+
+// maybePrefetchNext tops the prefetch queue up to prefetchChunks windows, starting immediately after the current
+// buffer (or the end of whatever is already queued), if prefetching is enabled and there is more data to read.
+func (rs *ReadSeeker) maybePrefetchNext() {
+	if !rs.prefetch || rs.bufOffset == -1 {
+		return
+	}
+	next := rs.bufOffset + int64(len(rs.buf)) + int64(len(rs.prefetchQueue))*rs.readAhead
+	if next > rs.size {
+		next = rs.size
+	}
+	for len(rs.prefetchQueue) < rs.prefetchChunks && next < rs.size {
+		windowLen := rs.readAhead
+		if next+windowLen > rs.size {
+			windowLen = rs.size - next
+		}
+		ch := make(chan prefetchResult, 1)
+		rs.prefetchQueue = append(rs.prefetchQueue, ch)
+		go func(offset int64, length int64) {
+			buf := make([]byte, length)
+			rs.sem <- struct{}{}
+			n, err := rs.read(offset, buf)
+			<-rs.sem
+			if err != nil && err != io.EOF {
+				ch <- prefetchResult{offset: offset, err: err}
+				return
+			}
+			ch <- prefetchResult{offset: offset, buf: buf[:n]}
+		}(next, windowLen)
+		next += windowLen
+	}
+}
+
+// consumePrefetch adopts the head of the prefetch queue as the current buffer if it has completed and covers
+// offset. A mismatch (a seek jumped past what was queued) discards the whole queue, since its remaining entries
+// are no longer contiguous with offset.
+func (rs *ReadSeeker) consumePrefetch(offset int64) bool {
+	if len(rs.prefetchQueue) == 0 {
+		return false
+	}
+	front := rs.prefetchQueue[0]
+	select {
+	case result := <-front:
+		rs.prefetchQueue = rs.prefetchQueue[1:]
+		if result.err != nil || result.offset != offset {
+			rs.prefetchQueue = nil
+			return false
+		}
+		rs.bufOffset = result.offset
+		rs.buf = result.buf
+		return true
+	default:
+		return false
+	}
+}
+
+// ReadAt implements io.ReaderAt, fetching the requested range directly rather than through the sequential window,
+// so concurrent callers can read distinct regions of the object in parallel without contending with Read's
+// cursor.
+func (rs *ReadSeeker) ReadAt(p []byte, offset int64) (int, error) {
+	if offset < 0 {
+		return 0, fmt.Errorf("fs: ReadAt: negative offset")
+	}
+	if offset >= rs.size {
+		return 0, io.EOF
+	}
+	want := p
+	if int64(len(want)) > rs.size-offset {
+		want = want[:rs.size-offset]
+	}
+	rs.sem <- struct{}{}
+	n, err := rs.read(offset, want)
+	<-rs.sem
+	if err != nil && err != io.EOF {
+		return n, err
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (rs *ReadSeeker) Read(p []byte) (int, error) {
+	if rs.offset >= rs.size {
+		return 0, io.EOF
+	}
+	if !rs.withinBuffer(rs.offset) {
+		if err := rs.fill(rs.offset, len(p)); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, rs.buf[rs.offset-rs.bufOffset:])
+	rs.offset += int64(n)
+	rs.maybePrefetchNext()
+	var err error
+	if rs.offset >= rs.size {
+		err = io.EOF
+	}
+	return n, err
+}
+
+func (rs *ReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	var target int64
+	switch whence {
+	case io.SeekStart:
+		target = offset
+	case io.SeekCurrent:
+		target = rs.offset + offset
+	case io.SeekEnd:
+		target = rs.size + offset
+	default:
+		return 0, io.ErrUnexpectedEOF
+	}
+
+	// a backward seek, or a forward seek outside the buffered window, invalidates any in-flight prefetches; the
+	// next Read reopens a fresh ranged fetch at the new offset.
+	if !rs.withinBuffer(target) {
+		rs.prefetchQueue = nil
+	}
+
+	rs.offset = target
+	return rs.offset, nil
+}
+
+// NewReadSeeker returns a ReadSeeker of size bytes starting at offset, fetching byte ranges via read. Reads that
+// fall within the current window are served from memory; a read outside it issues one call to read sized to at
+// least DefaultReadAheadSize (clamped to size), so sequential Reads coalesce into a handful of ranged fetches
+// rather than one per Read. Pass ReadSeekerOptions to change the window size, enable background prefetch, or bound
+// concurrent ranged fetches (including the ReadAt path); the zero-value defaults reproduce the original,
+// unconfigured behavior.
+func NewReadSeeker(offset int64, size int64, read func(offset int64, p []byte) (int, error), opts ...ReadSeekerOption) *ReadSeeker {
+	rs := &ReadSeeker{
+		read:      read,
+		size:      size,
+		readAhead: DefaultReadAheadSize,
+		sem:       make(chan struct{}, 1),
+		offset:    offset,
+		bufOffset: -1,
+	}
+	for _, opt := range opts {
+		opt(rs)
+	}
+	return rs
+}
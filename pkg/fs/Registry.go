@@ -0,0 +1,80 @@
+// =================================================================
+//
+// Work of the U.S. Department of Defense, Defense Digital Service.
+// Released as open source under the MIT License.  See LICENSE file.
+//
+// =================================================================
+
+package fs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Config is the subset of a configuration source (ordinarily a *viper.Viper) that an Opener needs to read
+// scheme-specific flags.  It lets openers live outside of cmd/icecube, and lets callers other than icecube's own
+// main package register and use openers without taking a dependency on viper.
+type Config interface {
+	GetString(key string) string
+	GetBool(key string) bool
+	GetInt(key string) int
+}
+
+// Opener builds the FileSystem identified by rawPath, which includes the "scheme://" prefix that selected it (e.g.
+// "s3://bucket/prefix").  config is consulted for any scheme-specific flags the opener needs (credentials,
+// endpoints, and the like); an opener must not read flags outside of its own scheme.
+type Opener func(ctx context.Context, rawPath string, config Config) (FileSystem, error)
+
+var (
+	openersMu sync.RWMutex
+	openers   = map[string]Opener{}
+)
+
+// Register registers opener under scheme, the portion of a root path before "://" (e.g. "s3", "gs", "vault").  A
+// root path with no "scheme://" prefix is dispatched to the "file" scheme.  Register panics if opener is nil or
+// scheme is already registered, mirroring the registration pattern used by database/sql drivers: it is meant to be
+// called from init() with a fixed set of built-in schemes, not with user-controlled input.
+func Register(scheme string, opener Opener) {
+	openersMu.Lock()
+	defer openersMu.Unlock()
+	if opener == nil {
+		panic("fs: Register opener is nil")
+	}
+	if _, dup := openers[scheme]; dup {
+		panic("fs: Register called twice for scheme " + scheme)
+	}
+	openers[scheme] = opener
+}
+
+// Scheme returns the scheme portion of rawPath: the text before "://", or "file" if rawPath has no such prefix.
+func Scheme(rawPath string) string {
+	if i := strings.Index(rawPath, "://"); i >= 0 {
+		return rawPath[:i]
+	}
+	return "file"
+}
+
+// Registered reports whether scheme has a registered Opener, so callers can validate a configured path before
+// attempting to open it.
+func Registered(scheme string) bool {
+	openersMu.RLock()
+	defer openersMu.RUnlock()
+	_, ok := openers[scheme]
+	return ok
+}
+
+// Open builds the FileSystem registered for rawPath's scheme, as reported by Scheme.  It returns an error if no
+// opener has been registered for that scheme.
+func Open(ctx context.Context, rawPath string, config Config) (FileSystem, error) {
+	scheme := Scheme(rawPath)
+	openersMu.RLock()
+	opener, ok := openers[scheme]
+	openersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("fs: no file system registered for scheme %q", scheme)
+	}
+	return opener(ctx, rawPath, config)
+}
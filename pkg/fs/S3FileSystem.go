@@ -12,13 +12,18 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	neturl "net/url"
+	"os"
 	"path"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	smithyendpoints "github.com/aws/smithy-go/endpoints"
 )
 
 type S3FileSystem struct {
@@ -27,6 +32,58 @@ type S3FileSystem struct {
 	s3                 *s3.Client
 	bucketCreationDate time.Time
 	maxEntries         int
+
+	readBufferSize      int64
+	prefetch            bool
+	prefetchChunks      int
+	maxConcurrentRanges int
+}
+
+// Option configures a S3FileSystem built by NewS3FileSystem or NewS3FileSystemWithConfig.
+type Option func(*S3FileSystem)
+
+// WithReadBufferSize overrides the size of the window fetched per ranged read on Open/OpenVersion
+// (DefaultReadAheadSize if unset).
+func WithReadBufferSize(size int64) Option {
+	return func(fs *S3FileSystem) {
+		fs.readBufferSize = size
+	}
+}
+
+// WithPartSize is an alias for WithReadBufferSize, matching the terminology used by S3-compatible client
+// libraries for the chunk size of a ranged read.
+func WithPartSize(size int64) Option {
+	return WithReadBufferSize(size)
+}
+
+// WithReadConcurrency bounds the number of in-flight ranged fetches per reader returned by Open/OpenVersion,
+// including background prefetches and ReadAt calls (1 if n <= 0).
+func WithReadConcurrency(n int) Option {
+	return func(fs *S3FileSystem) {
+		fs.maxConcurrentRanges = n
+	}
+}
+
+// WithPrefetch enables background prefetching on Open/OpenVersion and sets the number of windows kept pipelined
+// ahead of the read cursor (1 if chunks <= 0 once prefetching is enabled).
+func WithPrefetch(chunks int) Option {
+	return func(fs *S3FileSystem) {
+		fs.prefetch = true
+		fs.prefetchChunks = chunks
+	}
+}
+
+// readSeekerOptions translates the S3FileSystem's configured read buffer size, prefetch, and concurrency settings
+// into the ReadSeekerOptions NewReadSeeker expects.
+func (fs *S3FileSystem) readSeekerOptions() []ReadSeekerOption {
+	opts := []ReadSeekerOption{WithMaxConcurrentRanges(fs.maxConcurrentRanges)}
+	if fs.readBufferSize > 0 {
+		opts = append(opts, WithReadAheadSize(fs.readBufferSize))
+	}
+	if fs.prefetch {
+		opts = append(opts, WithPrefetchChunks(fs.prefetchChunks))
+	}
+	return opts
 }
 
 type S3DirectoryEntry struct {
@@ -62,21 +119,77 @@ func (fs *S3FileSystem) key(name string) string {
 	return fs.Join(fs.prefix, name)
 }
 
-func (fs *S3FileSystem) HeadObject(ctx context.Context, name string) (*FileInfo, error) {
+func (fs *S3FileSystem) HeadObject(ctx context.Context, name string) (FileInfo, error) {
+	start := time.Now()
 	headObjectOutput, err := fs.s3.HeadObject(ctx, &s3.HeadObjectInput{
 		Bucket: aws.String(fs.bucket),
 		Key:    aws.String(fs.key(name)),
 	})
+	S3BackendDuration.WithLabelValues(fs.bucket, "HeadObject").Observe(time.Since(start).Seconds())
 	if err != nil {
 		return nil, err
 	}
+	contentLength := aws.ToInt64(headObjectOutput.ContentLength)
 	fi := NewFileInfo(
 		name,
 		aws.ToTime(headObjectOutput.LastModified),
-		headObjectOutput.ContentLength == int64(0),
-		headObjectOutput.ContentLength,
+		contentLength == 0,
+		contentLength,
 	)
-	return fi, nil
+	return &s3FileInfoWithETag{
+		FileInfo: *fi,
+		etag:     aws.ToString(headObjectOutput.ETag),
+		metadata: headObjectMetadata(headObjectOutput),
+	}, nil
+}
+
+// Size returns the size in bytes of the object at name.
+func (fs *S3FileSystem) Size(ctx context.Context, name string) (int64, error) {
+	fi, err := fs.Stat(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	return fi.Size(), nil
+}
+
+// s3FileInfoWithETag decorates a FileInfo with the strong ETag and the user/standard metadata S3 returns from
+// HeadObject, so server.ServeContent can support conditional requests and {{meta .}} can render headers without
+// re-fetching the object.
+type s3FileInfoWithETag struct {
+	FileInfo
+	etag     string
+	metadata map[string]string
+}
+
+func (fi *s3FileInfoWithETag) ETag() string {
+	return fi.etag
+}
+
+func (fi *s3FileInfoWithETag) Metadata() map[string]string {
+	return fi.metadata
+}
+
+// headObjectMetadata flattens a HeadObject response's user-defined metadata along with the standard headers that
+// describe the object's representation (Content-Type, Content-Encoding, Cache-Control, ETag) into a single map,
+// for FileInfoWithMetadata.
+func headObjectMetadata(output *s3.HeadObjectOutput) map[string]string {
+	metadata := make(map[string]string, len(output.Metadata)+4)
+	for k, v := range output.Metadata {
+		metadata[k] = v
+	}
+	if v := aws.ToString(output.ContentType); v != "" {
+		metadata["Content-Type"] = v
+	}
+	if v := aws.ToString(output.ContentEncoding); v != "" {
+		metadata["Content-Encoding"] = v
+	}
+	if v := aws.ToString(output.CacheControl); v != "" {
+		metadata["Cache-Control"] = v
+	}
+	if v := aws.ToString(output.ETag); v != "" {
+		metadata["ETag"] = v
+	}
+	return metadata
 }
 
 func (fs *S3FileSystem) IsNotExist(err error) bool {
@@ -93,91 +206,94 @@ func (fs *S3FileSystem) Join(name ...string) string {
 	return path.Join(name...)
 }
 
+// ReadDirPage returns a single page of the directory listing for name, starting at token (the empty string starts at the beginning).
+// It is implemented with ListObjectsV2, passing token through verbatim as the ContinuationToken and returning the
+// server-provided NextContinuationToken verbatim, so pages can be resumed even if the bucket changes between calls.
+func (fs *S3FileSystem) ReadDirPage(ctx context.Context, name string, token string, limit int) ([]DirectoryEntry, string, error) {
+	listObjectsInput := &s3.ListObjectsV2Input{
+		Bucket:    aws.String(fs.bucket),
+		Delimiter: aws.String("/"),
+	}
+	if name != "/" {
+		listObjectsInput.Prefix = aws.String(fs.key(name) + "/")
+	} else {
+		listObjectsInput.Prefix = aws.String("")
+	}
+	if limit > 0 && limit < 1000 {
+		listObjectsInput.MaxKeys = aws.Int32(int32(limit))
+	}
+	if token != "" {
+		listObjectsInput.ContinuationToken = aws.String(token)
+	}
+
+	start := time.Now()
+	listObjectsOutput, err := fs.s3.ListObjectsV2(ctx, listObjectsInput)
+	S3BackendDuration.WithLabelValues(fs.bucket, "ListObjectsV2").Observe(time.Since(start).Seconds())
+	if err != nil {
+		return nil, "", err
+	}
+
+	directoryEntries := make([]DirectoryEntry, 0, len(listObjectsOutput.CommonPrefixes)+len(listObjectsOutput.Contents))
+	for _, commonPrefix := range listObjectsOutput.CommonPrefixes {
+		directoryEntries = append(directoryEntries, &S3DirectoryEntry{
+			name:    aws.ToString(commonPrefix.Prefix),
+			dir:     true,
+			modTime: fs.bucketCreationDate,
+			size:    0,
+		})
+	}
+	for _, object := range listObjectsOutput.Contents {
+		size := aws.ToInt64(object.Size)
+		directoryEntries = append(directoryEntries, &S3DirectoryEntry{
+			name:    aws.ToString(object.Key),
+			dir:     size == 0,
+			modTime: aws.ToTime(object.LastModified),
+			size:    size,
+		})
+	}
+
+	nextToken := ""
+	if aws.ToBool(listObjectsOutput.IsTruncated) {
+		nextToken = aws.ToString(listObjectsOutput.NextContinuationToken)
+	}
+
+	return directoryEntries, nextToken, nil
+}
+
+// ReadDir returns the full directory listing for name, driving ReadDirPage until the bucket is exhausted or
+// maxEntries is reached.  Unlike the previous implementation, there is no cap on the number of pages fetched.
 func (fs *S3FileSystem) ReadDir(ctx context.Context, name string) ([]DirectoryEntry, error) {
 	directoryEntries := []DirectoryEntry{}
-	// truncated, continuationToken, and startAfter are used to iterate through the bucket
-	//truncated := true
-	var marker *string
-	// if truncated continue iterating through the bucket
-	for i := 0; i < 20; i++ {
-		listObjectsInput := &s3.ListObjectsInput{
-			Bucket:    aws.String(fs.bucket),
-			Delimiter: aws.String("/"),
-		}
-		if fs.maxEntries != -1 && fs.maxEntries < 1000 {
-			listObjectsInput.MaxKeys = int32(fs.maxEntries)
-		}
-		if name != "/" {
-			listObjectsInput.Prefix = aws.String(fs.key(name) + "/")
-		} else {
-			listObjectsInput.Prefix = aws.String("")
-		}
-		if marker != nil {
-			listObjectsInput.Marker = marker
+	token := ""
+	for {
+		pageLimit := 0
+		if fs.maxEntries != -1 {
+			remaining := fs.maxEntries - len(directoryEntries)
+			if remaining <= 0 {
+				break
+			}
+			pageLimit = remaining
 		}
-		listObjectsOutput, err := fs.s3.ListObjects(ctx, listObjectsInput)
+		page, nextToken, err := fs.ReadDirPage(ctx, name, token, pageLimit)
 		if err != nil {
 			return nil, err
 		}
-		if fs.maxEntries != -1 {
-			// limit on number of directory entries
-			for _, commonPrefix := range listObjectsOutput.CommonPrefixes {
-				directoryEntries = append(directoryEntries, &S3DirectoryEntry{
-					name:    aws.ToString(commonPrefix.Prefix),
-					dir:     true,
-					modTime: fs.bucketCreationDate,
-					size:    0,
-				})
-				if len(directoryEntries) == fs.maxEntries {
-					break
-				}
-			}
-			if len(directoryEntries) == fs.maxEntries {
-				break
-			}
-			for _, object := range listObjectsOutput.Contents {
-				directoryEntries = append(directoryEntries, &S3DirectoryEntry{
-					name:    aws.ToString(object.Key),
-					dir:     (object.Size == 0),
-					modTime: aws.ToTime(object.LastModified),
-					size:    object.Size,
-				})
-				if len(directoryEntries) == fs.maxEntries {
-					break
-				}
-			}
-			if len(directoryEntries) == fs.maxEntries {
-				break
-			}
-		} else {
-			// no limit for number of directory entries
-			for _, commonPrefix := range listObjectsOutput.CommonPrefixes {
-				directoryEntries = append(directoryEntries, &S3DirectoryEntry{
-					name:    aws.ToString(commonPrefix.Prefix),
-					dir:     true,
-					modTime: fs.bucketCreationDate,
-					size:    0,
-				})
-			}
-			for _, object := range listObjectsOutput.Contents {
-				directoryEntries = append(directoryEntries, &S3DirectoryEntry{
-					name:    aws.ToString(object.Key),
-					dir:     (object.Size == 0),
-					modTime: aws.ToTime(object.LastModified),
-					size:    object.Size,
-				})
+		for _, entry := range page {
+			directoryEntries = append(directoryEntries, entry)
+			if fs.maxEntries != -1 && len(directoryEntries) == fs.maxEntries {
+				return directoryEntries, nil
 			}
 		}
-		if !listObjectsOutput.IsTruncated {
+		if nextToken == "" {
 			break
 		}
-		marker = listObjectsOutput.NextMarker
+		token = nextToken
 	}
 
 	return directoryEntries, nil
 }
 
-func (fs *S3FileSystem) Stat(ctx context.Context, name string) (*FileInfo, error) {
+func (fs *S3FileSystem) Stat(ctx context.Context, name string) (FileInfo, error) {
 	if name == "/" {
 		_, err := fs.s3.HeadBucket(ctx, &s3.HeadBucketInput{
 			Bucket: aws.String(fs.bucket),
@@ -221,11 +337,103 @@ func (fs *S3FileSystem) Open(ctx context.Context, name string) (io.ReadSeeker, e
 		0,
 		fi.Size(),
 		func(offset int64, p []byte) (int, error) {
+			start := time.Now()
 			getObjectOutput, err := fs.s3.GetObject(ctx, &s3.GetObjectInput{
 				Bucket: aws.String(fs.bucket),
 				Key:    aws.String(fs.key(name)),
 				Range:  aws.String(fmt.Sprintf("bytes=%d-%d", offset, int(offset)+len(p)-1)),
 			})
+			S3BackendDuration.WithLabelValues(fs.bucket, "GetObject").Observe(time.Since(start).Seconds())
+			if err != nil {
+				return 0, err
+			}
+			body, err := io.ReadAll(getObjectOutput.Body)
+			if err != nil {
+				return 0, err
+			}
+			copy(p, body)
+			return len(p), nil
+		},
+		fs.readSeekerOptions()...,
+	)
+	return rs, nil
+}
+
+// Create returns a writer that uploads to name when closed, making S3FileSystem satisfy WritableFileSystem.  Small
+// objects are uploaded with a single PutObject; objects above s3MultipartThreshold are uploaded as a multipart
+// upload.
+func (fs *S3FileSystem) Create(ctx context.Context, name string) (io.WriteCloser, error) {
+	return NewS3Writer(ctx, fs.s3, fs.bucket, fs.key(name)), nil
+}
+
+// Remove deletes the object at name.
+func (fs *S3FileSystem) Remove(ctx context.Context, name string) error {
+	_, err := fs.s3.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(fs.bucket),
+		Key:    aws.String(fs.key(name)),
+	})
+	return err
+}
+
+// MkdirAll is a no-op for S3, which has no directories of its own; object keys with a common prefix already behave
+// as a directory hierarchy.
+func (fs *S3FileSystem) MkdirAll(ctx context.Context, name string, perm os.FileMode) error {
+	return nil
+}
+
+// s3FileInfoWithVersion decorates a FileInfo with the S3 object version it was read from, as returned by
+// StatVersion.
+type s3FileInfoWithVersion struct {
+	FileInfo
+	versionID string
+}
+
+// VersionID returns the S3 object version this FileInfo describes.
+func (fi *s3FileInfoWithVersion) VersionID() string {
+	return fi.versionID
+}
+
+// StatVersion is like HeadObject, but reads a specific object version rather than the current one. Unlike Stat,
+// it only supports objects, not bucket or prefix ("directory") paths.
+func (fs *S3FileSystem) StatVersion(ctx context.Context, name string, versionID string) (FileInfo, error) {
+	start := time.Now()
+	headObjectOutput, err := fs.s3.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:    aws.String(fs.bucket),
+		Key:       aws.String(fs.key(name)),
+		VersionId: aws.String(versionID),
+	})
+	S3BackendDuration.WithLabelValues(fs.bucket, "HeadObject").Observe(time.Since(start).Seconds())
+	if err != nil {
+		return nil, err
+	}
+	contentLength := aws.ToInt64(headObjectOutput.ContentLength)
+	fi := NewFileInfo(
+		name,
+		aws.ToTime(headObjectOutput.LastModified),
+		contentLength == 0,
+		contentLength,
+	)
+	return &s3FileInfoWithVersion{FileInfo: fi, versionID: versionID}, nil
+}
+
+// OpenVersion is like Open, but reads a specific object version rather than the current one.
+func (fs *S3FileSystem) OpenVersion(ctx context.Context, name string, versionID string) (io.ReadSeeker, error) {
+	fi, err := fs.StatVersion(ctx, name, versionID)
+	if err != nil {
+		return nil, err
+	}
+	rs := NewReadSeeker(
+		0,
+		fi.Size(),
+		func(offset int64, p []byte) (int, error) {
+			start := time.Now()
+			getObjectOutput, err := fs.s3.GetObject(ctx, &s3.GetObjectInput{
+				Bucket:    aws.String(fs.bucket),
+				Key:       aws.String(fs.key(name)),
+				VersionId: aws.String(versionID),
+				Range:     aws.String(fmt.Sprintf("bytes=%d-%d", offset, int(offset)+len(p)-1)),
+			})
+			S3BackendDuration.WithLabelValues(fs.bucket, "GetObject").Observe(time.Since(start).Seconds())
 			if err != nil {
 				return 0, err
 			}
@@ -236,16 +444,206 @@ func (fs *S3FileSystem) Open(ctx context.Context, name string) (io.ReadSeeker, e
 			copy(p, body)
 			return len(p), nil
 		},
+		fs.readSeekerOptions()...,
 	)
 	return rs, nil
 }
 
-func NewS3FileSystem(bucket string, prefix string, s3 *s3.Client, bucketCreationDate time.Time, maxEntries int) *S3FileSystem {
-	return &S3FileSystem{
-		bucket:             bucket,
-		prefix:             prefix,
-		s3:                 s3,
-		bucketCreationDate: bucketCreationDate,
-		maxEntries:         maxEntries,
+// S3VersionedDirectoryEntry is a DirectoryEntry for a single version of an S3 object, as returned by
+// ReadDirVersions. Unlike S3DirectoryEntry, it may describe a non-current version or a delete marker, in addition
+// to the live, current-version entries ReadDir exposes.
+type S3VersionedDirectoryEntry struct {
+	name           string
+	dir            bool
+	modTime        time.Time
+	size           int64
+	versionID      string
+	isLatest       bool
+	isDeleteMarker bool
+}
+
+func (de *S3VersionedDirectoryEntry) IsDir() bool        { return de.dir }
+func (de *S3VersionedDirectoryEntry) Name() string       { return de.name }
+func (de *S3VersionedDirectoryEntry) ModTime() time.Time { return de.modTime }
+func (de *S3VersionedDirectoryEntry) Size() int64        { return de.size }
+
+// VersionID returns the S3 version ID of this entry.
+func (de *S3VersionedDirectoryEntry) VersionID() string { return de.versionID }
+
+// IsLatest reports whether this entry is the current version of the object.
+func (de *S3VersionedDirectoryEntry) IsLatest() bool { return de.isLatest }
+
+// IsDeleteMarker reports whether this entry is a delete marker rather than an object version.
+func (de *S3VersionedDirectoryEntry) IsDeleteMarker() bool { return de.isDeleteMarker }
+
+// ReadDirVersions lists every version of every object directly under name, including delete markers and
+// non-current versions, via ListObjectVersions. Subdirectories (common prefixes) are not listed, and entry names
+// are relative to name rather than the full object keys ReadDir returns.
+func (fs *S3FileSystem) ReadDirVersions(ctx context.Context, name string) ([]DirectoryEntry, error) {
+	prefix := ""
+	if name != "/" {
+		prefix = fs.key(name) + "/"
+	}
+
+	directoryEntries := []DirectoryEntry{}
+	var keyMarker, versionIDMarker *string
+	for {
+		listObjectVersionsOutput, err := fs.s3.ListObjectVersions(ctx, &s3.ListObjectVersionsInput{
+			Bucket:          aws.String(fs.bucket),
+			Prefix:          aws.String(prefix),
+			Delimiter:       aws.String("/"),
+			KeyMarker:       keyMarker,
+			VersionIdMarker: versionIDMarker,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error listing object versions of %q: %w", name, err)
+		}
+		for _, v := range listObjectVersionsOutput.Versions {
+			directoryEntries = append(directoryEntries, &S3VersionedDirectoryEntry{
+				name:      strings.TrimPrefix(aws.ToString(v.Key), prefix),
+				modTime:   aws.ToTime(v.LastModified),
+				size:      aws.ToInt64(v.Size),
+				versionID: aws.ToString(v.VersionId),
+				isLatest:  aws.ToBool(v.IsLatest),
+			})
+			if fs.maxEntries != -1 && len(directoryEntries) == fs.maxEntries {
+				return directoryEntries, nil
+			}
+		}
+		for _, dm := range listObjectVersionsOutput.DeleteMarkers {
+			directoryEntries = append(directoryEntries, &S3VersionedDirectoryEntry{
+				name:           strings.TrimPrefix(aws.ToString(dm.Key), prefix),
+				modTime:        aws.ToTime(dm.LastModified),
+				versionID:      aws.ToString(dm.VersionId),
+				isLatest:       aws.ToBool(dm.IsLatest),
+				isDeleteMarker: true,
+			})
+			if fs.maxEntries != -1 && len(directoryEntries) == fs.maxEntries {
+				return directoryEntries, nil
+			}
+		}
+		if !aws.ToBool(listObjectVersionsOutput.IsTruncated) {
+			break
+		}
+		keyMarker = listObjectVersionsOutput.NextKeyMarker
+		versionIDMarker = listObjectVersionsOutput.NextVersionIdMarker
+	}
+	return directoryEntries, nil
+}
+
+// GetBucketVersioning returns the versioning status of the bucket ("Enabled", "Suspended", or "" if versioning
+// has never been enabled).
+func (fs *S3FileSystem) GetBucketVersioning(ctx context.Context) (string, error) {
+	output, err := fs.s3.GetBucketVersioning(ctx, &s3.GetBucketVersioningInput{
+		Bucket: aws.String(fs.bucket),
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(output.Status), nil
+}
+
+func NewS3FileSystem(bucket string, prefix string, s3 *s3.Client, bucketCreationDate time.Time, maxEntries int, opts ...Option) *S3FileSystem {
+	s3fs := &S3FileSystem{
+		bucket:              bucket,
+		prefix:              prefix,
+		s3:                  s3,
+		bucketCreationDate:  bucketCreationDate,
+		maxEntries:          maxEntries,
+		readBufferSize:      DefaultReadAheadSize,
+		maxConcurrentRanges: 1,
+	}
+	for _, opt := range opts {
+		opt(s3fs)
+	}
+	return s3fs
+}
+
+// S3Config configures the S3-compatible client built by NewS3FileSystemWithConfig, letting callers target MinIO,
+// LocalStack, Ceph RGW, or any other S3-compatible endpoint instead of AWS S3 directly.
+type S3Config struct {
+	EndpointURL  string
+	UsePathStyle bool
+	DisableSSL   bool
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+	Profile      string
+}
+
+// s3EndpointResolverV2 resolves every request to a single, pre-parsed endpoint, optionally rewriting it to a
+// virtual-hosted-style host (bucket.host) when the client is not using path-style addressing.
+type s3EndpointResolverV2 struct {
+	url          neturl.URL
+	usePathStyle bool
+}
+
+func (r *s3EndpointResolverV2) ResolveEndpoint(ctx context.Context, params s3.EndpointParameters) (smithyendpoints.Endpoint, error) {
+	u := r.url
+	if !r.usePathStyle && aws.ToString(params.Bucket) != "" {
+		u.Host = aws.ToString(params.Bucket) + "." + u.Host
+	}
+	return smithyendpoints.Endpoint{URI: u}, nil
+}
+
+// NewS3FileSystemWithConfig builds the *s3.Client that NewS3FileSystem expects from cfg and region, and returns the
+// resulting S3FileSystem. Use this instead of NewS3FileSystem when targeting a non-AWS S3-compatible endpoint, or
+// when credentials need to be set explicitly rather than coming from the ambient AWS config.
+func NewS3FileSystemWithConfig(
+	ctx context.Context,
+	cfg S3Config,
+	region string,
+	bucket string,
+	prefix string,
+	bucketCreationDate time.Time,
+	maxEntries int,
+	opts ...Option) (*S3FileSystem, error) {
+
+	loadOpts := []func(*config.LoadOptions) error{config.WithRegion(region)}
+	if len(cfg.Profile) > 0 {
+		loadOpts = append(loadOpts, config.WithSharedConfigProfile(cfg.Profile))
+	}
+	if len(cfg.AccessKey) > 0 && len(cfg.SecretKey) > 0 {
+		loadOpts = append(loadOpts, config.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(cfg.AccessKey, cfg.SecretKey, cfg.SessionToken)))
+	}
+	awsCfg, err := config.LoadDefaultConfig(ctx, loadOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("fs: failed to load AWS config: %w", err)
+	}
+
+	optFns := []func(*s3.Options){
+		func(o *s3.Options) {
+			o.Region = region
+			o.UsePathStyle = cfg.UsePathStyle
+		},
+	}
+	if len(cfg.EndpointURL) > 0 {
+		u, err := neturl.Parse(cfg.EndpointURL)
+		if err != nil {
+			return nil, fmt.Errorf("fs: invalid endpoint URL %q: %w", cfg.EndpointURL, err)
+		}
+		if cfg.DisableSSL {
+			u.Scheme = "http"
+		}
+		resolver := &s3EndpointResolverV2{url: *u, usePathStyle: cfg.UsePathStyle}
+		optFns = append(optFns, func(o *s3.Options) {
+			o.EndpointResolverV2 = resolver
+		})
+	}
+	client := s3.NewFromConfig(awsCfg, optFns...)
+
+	return NewS3FileSystem(bucket, prefix, client, bucketCreationDate, maxEntries, opts...), nil
+}
+
+// Ping performs a cheap HeadBucket against the configured bucket, so callers can fail fast at startup against a
+// misconfigured endpoint or missing credentials rather than on the first real request.
+func (fs *S3FileSystem) Ping(ctx context.Context) error {
+	_, err := fs.s3.HeadBucket(ctx, &s3.HeadBucketInput{
+		Bucket: aws.String(fs.bucket),
+	})
+	if err != nil {
+		return fmt.Errorf("fs: HeadBucket failed for bucket %q: %w", fs.bucket, err)
 	}
+	return nil
 }
@@ -0,0 +1,129 @@
+// =================================================================
+//
+// Work of the U.S. Department of Defense, Defense Digital Service.
+// Released as open source under the MIT License.  See LICENSE file.
+//
+// =================================================================
+
+package fs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// s3MultipartThreshold is the object size above which S3Writer uses a multipart upload instead of a single PutObject.
+const s3MultipartThreshold = 16 * 1024 * 1024 // 16 MiB
+
+// s3PartSize is the size of each part uploaded once a write crosses s3MultipartThreshold.
+const s3PartSize = 8 * 1024 * 1024 // 8 MiB
+
+// S3Writer buffers writes for a single S3 object and, on Close, uploads them either as a single PutObject (small
+// objects) or as a multipart upload split into s3PartSize parts (objects above s3MultipartThreshold).
+type S3Writer struct {
+	ctx    context.Context
+	client *s3.Client
+	bucket string
+	key    string
+
+	buf            bytes.Buffer
+	uploadID       string
+	completedParts []types.CompletedPart
+}
+
+// NewS3Writer returns a S3Writer that uploads to bucket/key when Close is called.
+func NewS3Writer(ctx context.Context, client *s3.Client, bucket string, key string) *S3Writer {
+	return &S3Writer{
+		ctx:    ctx,
+		client: client,
+		bucket: bucket,
+		key:    key,
+	}
+}
+
+func (w *S3Writer) Write(p []byte) (int, error) {
+	n, err := w.buf.Write(p)
+	if err != nil {
+		return n, err
+	}
+	if w.uploadID == "" && w.buf.Len() <= s3MultipartThreshold {
+		return n, nil
+	}
+	for w.buf.Len() >= s3PartSize {
+		if err := w.flushPart(s3PartSize); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// flushPart uploads the next size bytes of the buffer as a part of a multipart upload, creating the upload on first
+// use.
+func (w *S3Writer) flushPart(size int) error {
+	if w.uploadID == "" {
+		createOutput, err := w.client.CreateMultipartUpload(w.ctx, &s3.CreateMultipartUploadInput{
+			Bucket: aws.String(w.bucket),
+			Key:    aws.String(w.key),
+		})
+		if err != nil {
+			return fmt.Errorf("error creating multipart upload for %q: %w", w.key, err)
+		}
+		w.uploadID = aws.ToString(createOutput.UploadId)
+	}
+	partNumber := int32(len(w.completedParts) + 1)
+	uploadPartOutput, err := w.client.UploadPart(w.ctx, &s3.UploadPartInput{
+		Bucket:     aws.String(w.bucket),
+		Key:        aws.String(w.key),
+		UploadId:   aws.String(w.uploadID),
+		PartNumber: aws.Int32(partNumber),
+		Body:       bytes.NewReader(w.buf.Next(size)),
+	})
+	if err != nil {
+		return fmt.Errorf("error uploading part %d for %q: %w", partNumber, w.key, err)
+	}
+	w.completedParts = append(w.completedParts, types.CompletedPart{
+		ETag:       uploadPartOutput.ETag,
+		PartNumber: aws.Int32(partNumber),
+	})
+	return nil
+}
+
+// Close flushes any remaining buffered data and finalizes the upload.
+func (w *S3Writer) Close() error {
+	// small object, or nothing was ever large enough to trigger a multipart upload: a single PutObject suffices.
+	if w.uploadID == "" {
+		_, err := w.client.PutObject(w.ctx, &s3.PutObjectInput{
+			Bucket: aws.String(w.bucket),
+			Key:    aws.String(w.key),
+			Body:   bytes.NewReader(w.buf.Bytes()),
+		})
+		if err != nil {
+			return fmt.Errorf("error putting object %q: %w", w.key, err)
+		}
+		return nil
+	}
+
+	if w.buf.Len() > 0 {
+		if err := w.flushPart(w.buf.Len()); err != nil {
+			return err
+		}
+	}
+
+	_, err := w.client.CompleteMultipartUpload(w.ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:   aws.String(w.bucket),
+		Key:      aws.String(w.key),
+		UploadId: aws.String(w.uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{
+			Parts: w.completedParts,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("error completing multipart upload for %q: %w", w.key, err)
+	}
+	return nil
+}
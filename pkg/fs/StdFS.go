@@ -0,0 +1,223 @@
+// =================================================================
+//
+// Work of the U.S. Department of Defense, Defense Digital Service.
+// Released as open source under the MIT License.  See LICENSE file.
+//
+// =================================================================
+
+package fs
+
+import (
+	"context"
+	"io"
+	iofs "io/fs"
+	"time"
+)
+
+// stdFileInfo adapts a FileInfo to the standard io/fs.FileInfo interface.
+type stdFileInfo struct {
+	fi FileInfo
+}
+
+func (s stdFileInfo) Name() string { return s.fi.Name() }
+func (s stdFileInfo) Size() int64  { return s.fi.Size() }
+
+func (s stdFileInfo) Mode() iofs.FileMode {
+	if s.fi.IsDir() {
+		return iofs.ModeDir | 0555
+	}
+	return 0444
+}
+func (s stdFileInfo) ModTime() time.Time { return s.fi.ModTime() }
+func (s stdFileInfo) IsDir() bool        { return s.fi.IsDir() }
+func (s stdFileInfo) Sys() interface{}   { return nil }
+
+// stdDirEntry adapts a DirectoryEntry to the standard io/fs.DirEntry interface.  DirectoryEntry carries no mode bits.
+type stdDirEntry struct {
+	de DirectoryEntry
+}
+
+func (d stdDirEntry) Name() string { return d.de.Name() }
+func (d stdDirEntry) IsDir() bool  { return d.de.IsDir() }
+
+func (d stdDirEntry) Type() iofs.FileMode {
+	if d.de.IsDir() {
+		return iofs.ModeDir
+	}
+	return 0
+}
+
+// Info reports the entry's size when de implements DirectoryEntryWithSize (true of every backend wired to AsFS:
+// S3DirectoryEntry, LocalDirectoryEntry), and 0 otherwise, matching os.FileInfo's convention of an unspecified size
+// for directories.
+func (d stdDirEntry) Info() (iofs.FileInfo, error) {
+	var size int64
+	if withSize, ok := d.de.(DirectoryEntryWithSize); ok {
+		size = withSize.Size()
+	}
+	return stdFileInfo{fi: NewFileInfo(d.de.Name(), d.de.ModTime(), d.de.IsDir(), size)}, nil
+}
+
+// mapNotExist translates a backend-specific not-found error into the sentinel iofs.ErrNotExist expected by io/fs
+// consumers such as fstest.TestFS.
+func mapNotExist(filesystem FileSystem, err error) error {
+	if filesystem.IsNotExist(err) {
+		return iofs.ErrNotExist
+	}
+	return err
+}
+
+// stdFile adapts the io.ReadSeeker returned by FileSystem.Open to the standard io/fs.File interface.
+type stdFile struct {
+	io.ReadSeeker
+	info FileInfo
+}
+
+func (f *stdFile) Stat() (iofs.FileInfo, error) { return stdFileInfo{fi: f.info}, nil }
+
+func (f *stdFile) Close() error {
+	if closer, ok := f.ReadSeeker.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// stdDir adapts a directory listing to the standard io/fs.ReadDirFile interface.
+type stdDir struct {
+	info    FileInfo
+	entries []DirectoryEntry
+	offset  int
+}
+
+func (d *stdDir) Stat() (iofs.FileInfo, error) { return stdFileInfo{fi: d.info}, nil }
+
+func (d *stdDir) Read([]byte) (int, error) {
+	return 0, &iofs.PathError{Op: "read", Path: d.info.Name(), Err: iofs.ErrInvalid}
+}
+
+func (d *stdDir) Close() error { return nil }
+
+func (d *stdDir) ReadDir(n int) ([]iofs.DirEntry, error) {
+	if n <= 0 {
+		out := make([]iofs.DirEntry, 0, len(d.entries)-d.offset)
+		for _, entry := range d.entries[d.offset:] {
+			out = append(out, stdDirEntry{de: entry})
+		}
+		d.offset = len(d.entries)
+		return out, nil
+	}
+	if d.offset >= len(d.entries) {
+		return nil, io.EOF
+	}
+	end := d.offset + n
+	if end > len(d.entries) {
+		end = len(d.entries)
+	}
+	out := make([]iofs.DirEntry, 0, end-d.offset)
+	for _, entry := range d.entries[d.offset:end] {
+		out = append(out, stdDirEntry{de: entry})
+	}
+	d.offset = end
+	return out, nil
+}
+
+// FileSystemFS adapts a FileSystem to the standard io/fs.FS, io/fs.ReadDirFS, io/fs.StatFS, and io/fs.SubFS
+// interfaces, so icecube's backends can be consumed by stdlib and third-party libraries (http.FileServerFS,
+// html/template.ParseFS, fstest.TestFS, archive/zip, ...).  io/fs.FS has no notion of a request context, so every
+// operation uses context.Background().
+type FileSystemFS struct {
+	fs     FileSystem
+	prefix string // joined onto every name before calling fs, used to implement Sub
+}
+
+// newFileSystemFS returns a FileSystemFS wrapping underlying, rooted at "/".
+func newFileSystemFS(underlying FileSystem) *FileSystemFS {
+	return &FileSystemFS{fs: underlying}
+}
+
+// resolve validates name as an io/fs path and joins it onto the sub-tree's prefix, returning the absolute path
+// expected by FileSystem.
+func (f *FileSystemFS) resolve(name string) (string, error) {
+	if !iofs.ValidPath(name) {
+		return "", iofs.ErrInvalid
+	}
+	if name == "." {
+		return f.fs.Join("/", f.prefix), nil
+	}
+	return f.fs.Join("/", f.prefix, name), nil
+}
+
+func (f *FileSystemFS) Open(name string) (iofs.File, error) {
+	resolved, err := f.resolve(name)
+	if err != nil {
+		return nil, &iofs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	ctx := context.Background()
+	fi, err := f.fs.Stat(ctx, resolved)
+	if err != nil {
+		return nil, &iofs.PathError{Op: "open", Path: name, Err: mapNotExist(f.fs, err)}
+	}
+
+	if fi.IsDir() {
+		entries, err := f.fs.ReadDir(ctx, resolved)
+		if err != nil {
+			return nil, &iofs.PathError{Op: "open", Path: name, Err: mapNotExist(f.fs, err)}
+		}
+		return &stdDir{info: fi, entries: entries}, nil
+	}
+
+	content, err := f.fs.Open(ctx, resolved)
+	if err != nil {
+		return nil, &iofs.PathError{Op: "open", Path: name, Err: mapNotExist(f.fs, err)}
+	}
+	return &stdFile{ReadSeeker: content, info: fi}, nil
+}
+
+func (f *FileSystemFS) ReadDir(name string) ([]iofs.DirEntry, error) {
+	resolved, err := f.resolve(name)
+	if err != nil {
+		return nil, &iofs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+	entries, err := f.fs.ReadDir(context.Background(), resolved)
+	if err != nil {
+		return nil, &iofs.PathError{Op: "readdir", Path: name, Err: mapNotExist(f.fs, err)}
+	}
+	out := make([]iofs.DirEntry, 0, len(entries))
+	for _, entry := range entries {
+		out = append(out, stdDirEntry{de: entry})
+	}
+	return out, nil
+}
+
+func (f *FileSystemFS) Stat(name string) (iofs.FileInfo, error) {
+	resolved, err := f.resolve(name)
+	if err != nil {
+		return nil, &iofs.PathError{Op: "stat", Path: name, Err: err}
+	}
+	fi, err := f.fs.Stat(context.Background(), resolved)
+	if err != nil {
+		return nil, &iofs.PathError{Op: "stat", Path: name, Err: mapNotExist(f.fs, err)}
+	}
+	return stdFileInfo{fi: fi}, nil
+}
+
+func (f *FileSystemFS) Sub(dir string) (iofs.FS, error) {
+	if !iofs.ValidPath(dir) {
+		return nil, &iofs.PathError{Op: "sub", Path: dir, Err: iofs.ErrInvalid}
+	}
+	if dir == "." {
+		return f, nil
+	}
+	return &FileSystemFS{fs: f.fs, prefix: f.fs.Join(f.prefix, dir)}, nil
+}
+
+// AsFS exposes fs as a standard io/fs.FS, satisfying io/fs.ReadDirFS, io/fs.StatFS, and io/fs.SubFS as well.
+func (fs *LocalFileSystem) AsFS() iofs.FS {
+	return newFileSystemFS(fs)
+}
+
+// AsFS exposes fs as a standard io/fs.FS, satisfying io/fs.ReadDirFS, io/fs.StatFS, and io/fs.SubFS as well.
+func (fs *S3FileSystem) AsFS() iofs.FS {
+	return newFileSystemFS(fs)
+}
@@ -0,0 +1,53 @@
+// =================================================================
+//
+// Work of the U.S. Department of Defense, Defense Digital Service.
+// Released as open source under the MIT License.  See LICENSE file.
+//
+// =================================================================
+
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFileSystemFS(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "a.txt"), []byte("hello"), 0644); err != nil {
+		t.Fatalf("error writing a.txt: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(root, "sub"), 0755); err != nil {
+		t.Fatalf("error creating sub: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "sub", "b.txt"), []byte("hello, world"), 0644); err != nil {
+		t.Fatalf("error writing sub/b.txt: %v", err)
+	}
+
+	fsys := NewLocalFileSystem(root).AsFS()
+
+	if err := fstest.TestFS(fsys, "a.txt", "sub", "sub/b.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := fsys.(interface {
+		ReadDir(name string) ([]os.DirEntry, error)
+	}).ReadDir(".")
+	if err != nil {
+		t.Fatalf("error reading directory: %v", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			t.Fatalf("error getting info for %q: %v", entry.Name(), err)
+		}
+		if info.Size() == 0 {
+			t.Errorf("expected non-zero size for file %q, got 0", entry.Name())
+		}
+	}
+}
@@ -0,0 +1,161 @@
+// =================================================================
+//
+// Work of the U.S. Department of Defense, Defense Digital Service.
+// Released as open source under the MIT License.  See LICENSE file.
+//
+// =================================================================
+
+package fs
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// VaultFileSystem is a read-only FileSystem backed by a HashiCorp Vault KV version 2 secrets engine, rooted at
+// prefix under mount.  It is opened for vault:// roots in --root or --file-systems; --server-key-pairs and
+// --acme-cache-dir do not read through it.  A "file" is a KV secret, and its content is the base64-encoded
+// string stored under the "value" field of that secret's data.
+type VaultFileSystem struct {
+	client *api.Client
+	mount  string
+	prefix string
+}
+
+type VaultDirectoryEntry struct {
+	name string
+	dir  bool
+}
+
+func (de *VaultDirectoryEntry) IsDir() bool        { return de.dir }
+func (de *VaultDirectoryEntry) Name() string       { return de.name }
+func (de *VaultDirectoryEntry) ModTime() time.Time { return time.Time{} }
+func (de *VaultDirectoryEntry) Size() int64        { return 0 }
+
+func (fs *VaultFileSystem) key(name string) string {
+	if len(fs.prefix) == 0 {
+		return strings.TrimPrefix(name, "/")
+	}
+	return fs.Join(fs.prefix, name)
+}
+
+func (fs *VaultFileSystem) IsNotExist(err error) bool {
+	var responseErr *api.ResponseError
+	if errors.As(err, &responseErr) {
+		return responseErr.StatusCode == 404
+	}
+	return errors.Is(err, errVaultSecretNotFound)
+}
+
+var errVaultSecretNotFound = errors.New("vault: secret not found")
+
+func (fs *VaultFileSystem) Join(name ...string) string {
+	return path.Join(name...)
+}
+
+// ReadDir lists the secrets and sub-paths one level under name via Vault's KV v2 list endpoint
+// (mount/metadata/key).
+func (fs *VaultFileSystem) ReadDir(ctx context.Context, name string) ([]DirectoryEntry, error) {
+	key := fs.key(name)
+	secret, err := fs.client.Logical().ListWithContext(ctx, path.Join(fs.mount, "metadata", key))
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.Data == nil {
+		return []DirectoryEntry{}, nil
+	}
+	keysRaw, ok := secret.Data["keys"].([]interface{})
+	if !ok {
+		return []DirectoryEntry{}, nil
+	}
+	directoryEntries := make([]DirectoryEntry, 0, len(keysRaw))
+	for _, k := range keysRaw {
+		keyName, _ := k.(string)
+		directoryEntries = append(directoryEntries, &VaultDirectoryEntry{
+			name: keyName,
+			dir:  strings.HasSuffix(keyName, "/"),
+		})
+	}
+	return directoryEntries, nil
+}
+
+func (fs *VaultFileSystem) Size(ctx context.Context, name string) (int64, error) {
+	fi, err := fs.Stat(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	return fi.Size(), nil
+}
+
+func (fs *VaultFileSystem) Stat(ctx context.Context, name string) (FileInfo, error) {
+	if name == "/" {
+		return NewFileInfo(name, time.Time{}, true, int64(0)), nil
+	}
+
+	data, err := fs.readValue(ctx, name)
+	if err == nil {
+		return NewFileInfo(name, time.Time{}, false, int64(len(data))), nil
+	}
+	if !errors.Is(err, errVaultSecretNotFound) {
+		return nil, err
+	}
+
+	directoryEntries, dirErr := fs.ReadDir(ctx, name)
+	if dirErr == nil && len(directoryEntries) > 0 {
+		return NewFileInfo(name, time.Time{}, true, int64(0)), nil
+	}
+	return nil, err
+}
+
+// readValue reads the "value" field of the KV v2 secret at name, decoding it from base64.
+func (fs *VaultFileSystem) readValue(ctx context.Context, name string) ([]byte, error) {
+	key := fs.key(name)
+	secret, err := fs.client.Logical().ReadWithContext(ctx, path.Join(fs.mount, "data", key))
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, errVaultSecretNotFound
+	}
+	fields, ok := secret.Data["data"].(map[string]interface{})
+	if !ok {
+		return nil, errVaultSecretNotFound
+	}
+	value, ok := fields["value"].(string)
+	if !ok {
+		return nil, fmt.Errorf("vault: secret %q has no \"value\" field", key)
+	}
+	return base64.StdEncoding.DecodeString(value)
+}
+
+func (fs *VaultFileSystem) Open(ctx context.Context, name string) (io.ReadSeeker, error) {
+	data, err := fs.readValue(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return NewReadSeeker(
+		0,
+		int64(len(data)),
+		func(offset int64, p []byte) (int, error) {
+			return copy(p, data[offset:]), nil
+		},
+	), nil
+}
+
+// NewVaultFileSystem returns a VaultFileSystem that reads secrets under prefix from the KV v2 engine mounted at
+// mount, using client to talk to Vault.
+func NewVaultFileSystem(client *api.Client, mount string, prefix string) *VaultFileSystem {
+	return &VaultFileSystem{
+		client: client,
+		mount:  mount,
+		prefix: prefix,
+	}
+}
@@ -0,0 +1,23 @@
+// =================================================================
+//
+// Work of the U.S. Department of Defense, Defense Digital Service.
+// Released as open source under the MIT License.  See LICENSE file.
+//
+// =================================================================
+
+package fs
+
+import (
+	"context"
+	"io"
+	"os"
+)
+
+// WritableFileSystem is implemented by file systems that support creating, removing, and making directories for
+// objects, in addition to the read-only FileSystem operations.
+type WritableFileSystem interface {
+	FileSystem
+	Create(ctx context.Context, name string) (io.WriteCloser, error)
+	Remove(ctx context.Context, name string) error
+	MkdirAll(ctx context.Context, name string, perm os.FileMode) error
+}
@@ -0,0 +1,170 @@
+// =================================================================
+//
+// Work of the U.S. Department of Defense, Defense Digital Service.
+// Released as open source under the MIT License.  See LICENSE file.
+//
+// =================================================================
+
+// Package wkfs registers icecube's pkg/fs.S3FileSystem as a go4.org/wkfs well-known filesystem under the "/s3/"
+// prefix, so other tools in the DoD ecosystem that already speak wkfs.FileSystem can read and write S3 objects
+// without importing icecube's internal pkg/fs.  All "/s3/" paths are served from the single bucket given to
+// Register; the first path element is not read as a bucket name.
+package wkfs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"go4.org/wkfs"
+
+	"github.com/deptofdefense/icecube/pkg/fs"
+)
+
+// Options configures the S3FileSystem registered by Register. Endpoint targets a non-AWS S3-compatible service
+// (e.g. MinIO) instead of AWS S3 directly.
+type Options struct {
+	Region    string
+	Bucket    string
+	Profile   string
+	AccessKey string
+	SecretKey string
+	Endpoint  string
+}
+
+// Register registers a S3FileSystem built from opts under the wkfs "/s3/" prefix. After calling Register, paths
+// like "/s3/key" passed to wkfs.Open, wkfs.Stat, etc. are served by opts.Bucket.
+func Register(ctx context.Context, opts Options) error {
+	s3fs, err := fs.NewS3FileSystemWithConfig(
+		ctx,
+		fs.S3Config{
+			EndpointURL: opts.Endpoint,
+			AccessKey:   opts.AccessKey,
+			SecretKey:   opts.SecretKey,
+			Profile:     opts.Profile,
+		},
+		opts.Region,
+		opts.Bucket,
+		"",
+		time.Time{},
+		-1,
+	)
+	if err != nil {
+		return err
+	}
+	wkfs.RegisterFS("/s3/", &s3WkFS{fs: s3fs})
+	return nil
+}
+
+// s3WkFS adapts a *fs.S3FileSystem to wkfs.FileSystem.
+type s3WkFS struct {
+	fs *fs.S3FileSystem
+}
+
+func (wfs *s3WkFS) Open(filename string) (wkfs.File, error) {
+	fi, err := wfs.fs.Stat(context.Background(), filename)
+	if err != nil {
+		return nil, translateNotExist(err)
+	}
+	rs, err := wfs.fs.Open(context.Background(), filename)
+	if err != nil {
+		return nil, translateNotExist(err)
+	}
+	return &readableFile{ReadSeeker: rs, fi: &fileInfo{fi}, name: filename}, nil
+}
+
+func (wfs *s3WkFS) Stat(filename string) (os.FileInfo, error) {
+	return wfs.Lstat(filename)
+}
+
+func (wfs *s3WkFS) Lstat(filename string) (os.FileInfo, error) {
+	fi, err := wfs.fs.Stat(context.Background(), filename)
+	if err != nil {
+		return nil, translateNotExist(err)
+	}
+	return &fileInfo{fi}, nil
+}
+
+// MkdirAll is a no-op: S3 has no directories of its own, so there is nothing to create.
+func (wfs *s3WkFS) MkdirAll(path string, perm os.FileMode) error {
+	return nil
+}
+
+// OpenFile maps a create/write-flagged open to Create and rejects a read-only open with an error. S3 objects are
+// written in a single Close, so O_APPEND and partial writes to an existing object are not supported.
+func (wfs *s3WkFS) OpenFile(filename string, flag int, perm os.FileMode) (wkfs.FileWriter, error) {
+	if flag&(os.O_CREATE|os.O_WRONLY|os.O_RDWR) == 0 {
+		return nil, errors.New("wkfs/fs: OpenFile requires O_CREATE, O_WRONLY, or O_RDWR")
+	}
+	w, err := wfs.fs.Create(context.Background(), filename)
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Remove maps to DeleteObject.
+func (wfs *s3WkFS) Remove(filename string) error {
+	err := wfs.fs.Remove(context.Background(), filename)
+	if err != nil {
+		return translateNotExist(err)
+	}
+	return nil
+}
+
+// translateNotExist converts the *http.ResponseError a 404 surfaces as into os.ErrNotExist, so callers that use
+// os.IsNotExist against a wkfs path get the answer they expect.
+func translateNotExist(err error) error {
+	var responseError *http.ResponseError
+	if errors.As(err, &responseError) && responseError.HTTPStatusCode() == 404 {
+		return os.ErrNotExist
+	}
+	return err
+}
+
+type readableFile struct {
+	io.ReadSeeker
+	fi   os.FileInfo
+	name string
+}
+
+func (f *readableFile) Close() error {
+	return nil
+}
+
+func (f *readableFile) Name() string {
+	return f.name
+}
+
+func (f *readableFile) ReadAt(p []byte, off int64) (int, error) {
+	ra, ok := f.ReadSeeker.(io.ReaderAt)
+	if !ok {
+		return 0, errors.New("wkfs/fs: underlying reader does not support ReadAt")
+	}
+	return ra.ReadAt(p, off)
+}
+
+func (f *readableFile) Stat() (os.FileInfo, error) {
+	return f.fi, nil
+}
+
+// fileInfo adapts a pkg/fs.FileInfo to os.FileInfo, as required by wkfs.FileSystem.
+type fileInfo struct {
+	fi fs.FileInfo
+}
+
+func (fi *fileInfo) Name() string       { return strings.TrimPrefix(fi.fi.Name(), "/") }
+func (fi *fileInfo) Size() int64        { return fi.fi.Size() }
+func (fi *fileInfo) ModTime() time.Time { return fi.fi.ModTime() }
+func (fi *fileInfo) IsDir() bool        { return fi.fi.IsDir() }
+func (fi *fileInfo) Sys() interface{}   { return nil }
+func (fi *fileInfo) Mode() os.FileMode {
+	if fi.fi.IsDir() {
+		return os.ModeDir | 0755
+	}
+	return 0644
+}
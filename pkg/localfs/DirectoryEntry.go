@@ -0,0 +1,46 @@
+// =================================================================
+//
+// Work of the U.S. Department of Defense, Defense Digital Service.
+// Released as open source under the MIT License.  See LICENSE file.
+//
+// =================================================================
+
+package localfs
+
+import (
+	"time"
+)
+
+// LocalDirectoryEntry is a DirectoryEntry backed directly by a real directory entry, mirroring the struct shape
+// of fs.S3DirectoryEntry.
+type LocalDirectoryEntry struct {
+	name    string
+	dir     bool
+	modTime time.Time
+	size    int64
+}
+
+func (de *LocalDirectoryEntry) IsDir() bool {
+	return de.dir
+}
+
+func (de *LocalDirectoryEntry) Name() string {
+	return de.name
+}
+
+func (de *LocalDirectoryEntry) ModTime() time.Time {
+	return de.modTime
+}
+
+func (de *LocalDirectoryEntry) Size() int64 {
+	return de.size
+}
+
+func NewLocalDirectoryEntry(name string, dir bool, modTime time.Time, size int64) *LocalDirectoryEntry {
+	return &LocalDirectoryEntry{
+		name:    name,
+		dir:     dir,
+		modTime: modTime,
+		size:    size,
+	}
+}
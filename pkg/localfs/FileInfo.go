@@ -0,0 +1,46 @@
+// =================================================================
+//
+// Work of the U.S. Department of Defense, Defense Digital Service.
+// Released as open source under the MIT License.  See LICENSE file.
+//
+// =================================================================
+
+package localfs
+
+import (
+	"time"
+)
+
+// LocalFileInfo is a FileInfo backed directly by a real os.FileInfo, with the same four fields (name, size,
+// modification time, directory flag) as the FileInfo that fs.NewFileInfo returns.
+type LocalFileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	dir     bool
+}
+
+func (fi *LocalFileInfo) IsDir() bool {
+	return fi.dir
+}
+
+func (fi *LocalFileInfo) Name() string {
+	return fi.name
+}
+
+func (fi *LocalFileInfo) ModTime() time.Time {
+	return fi.modTime
+}
+
+func (fi *LocalFileInfo) Size() int64 {
+	return fi.size
+}
+
+func NewLocalFileInfo(name string, modTime time.Time, dir bool, size int64) *LocalFileInfo {
+	return &LocalFileInfo{
+		name:    name,
+		modTime: modTime,
+		dir:     dir,
+		size:    size,
+	}
+}
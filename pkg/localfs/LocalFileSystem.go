@@ -0,0 +1,192 @@
+// =================================================================
+//
+// Work of the U.S. Department of Defense, Defense Digital Service.
+// Released as open source under the MIT License.  See LICENSE file.
+//
+// =================================================================
+
+// Package localfs is a production-grade FileSystem backed directly by os/filepath, rooted at a real directory on
+// disk.  It exists alongside fs.LocalFileSystem (which wraps afero, and is kept for its existing callers and for
+// swapping in alternate afero backends such as an in-memory fs during tests): LocalFileSystem here resolves every
+// requested path's symlinks and rejects any that land outside of root, so serving operator-supplied content from
+// disk doesn't risk a symlink planted inside the served tree escaping it, and it checks context cancellation
+// between batches of a large ReadDir rather than only before or after the whole call.
+package localfs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/deptofdefense/icecube/pkg/fs"
+)
+
+// readDirBatchSize bounds how many directory entries ReadDir reads from the OS per iteration, so ctx is checked
+// between batches instead of only before or after the whole listing.
+const readDirBatchSize = 512
+
+// LocalFileSystem is a FileSystem rooted at root, a real directory on disk resolved (and symlink-evaluated) once
+// at construction.  Every path it's asked to operate on is re-resolved and checked against root on each call, so
+// root being replaced with a symlink after startup can't be used to escape it.
+type LocalFileSystem struct {
+	root string
+}
+
+// resolve returns the real, absolute path of name within fs's root, rejecting any path — including one reached
+// by following a symlink partway down — that resolves outside of root.
+func (lfs *LocalFileSystem) resolve(name string) (string, error) {
+	cleaned := filepath.Join(lfs.root, filepath.FromSlash(path.Clean("/"+name)))
+
+	resolved, err := lfs.resolveExisting(cleaned)
+	if err != nil {
+		return "", err
+	}
+
+	if resolved != lfs.root && !strings.HasPrefix(resolved, lfs.root+string(os.PathSeparator)) {
+		return "", fmt.Errorf("localfs: path %q escapes root %q", name, lfs.root)
+	}
+	return resolved, nil
+}
+
+// resolveExisting evaluates symlinks in cleaned, walking up to the nearest existing ancestor (cleaned itself, or
+// at worst lfs.root, which is guaranteed to exist) when cleaned or one of its parents doesn't exist yet — e.g. a
+// MkdirAll or Create target nested two or more levels under a destination prefix that hasn't been created yet —
+// and recombines the evaluated ancestor with the non-existent suffix.  A single-step retry against just the
+// immediate parent isn't enough: a multi-level-missing target would still fail with the parent's own ENOENT.
+func (lfs *LocalFileSystem) resolveExisting(cleaned string) (string, error) {
+	suffix := ""
+	current := cleaned
+	for {
+		resolved, err := filepath.EvalSymlinks(current)
+		if err == nil {
+			return filepath.Join(resolved, suffix), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return "", err
+		}
+		suffix = filepath.Join(filepath.Base(current), suffix)
+		current = parent
+	}
+}
+
+func (lfs *LocalFileSystem) IsNotExist(err error) bool {
+	return os.IsNotExist(err)
+}
+
+func (lfs *LocalFileSystem) Join(name ...string) string {
+	return path.Join(name...)
+}
+
+// ReadDir lists the entries of name, reading them from the OS in batches of readDirBatchSize and checking ctx for
+// cancellation between each, so a caller can give up on a very large or slow (e.g. network-mounted) directory
+// without waiting for the whole listing to finish.
+func (lfs *LocalFileSystem) ReadDir(ctx context.Context, name string) ([]fs.DirectoryEntry, error) {
+	resolved, err := lfs.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	d, err := os.Open(resolved)
+	if err != nil {
+		return nil, err
+	}
+	defer d.Close()
+
+	directoryEntries := []fs.DirectoryEntry{}
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		batch, readErr := d.ReadDir(readDirBatchSize)
+		for _, entry := range batch {
+			info, infoErr := entry.Info()
+			if infoErr != nil {
+				return nil, infoErr
+			}
+			directoryEntries = append(directoryEntries, NewLocalDirectoryEntry(entry.Name(), entry.IsDir(), info.ModTime(), info.Size()))
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return nil, readErr
+		}
+	}
+	return directoryEntries, nil
+}
+
+func (lfs *LocalFileSystem) Size(ctx context.Context, name string) (int64, error) {
+	fi, err := lfs.Stat(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	return fi.Size(), nil
+}
+
+func (lfs *LocalFileSystem) Stat(ctx context.Context, name string) (fs.FileInfo, error) {
+	resolved, err := lfs.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return nil, err
+	}
+	return NewLocalFileInfo(name, info.ModTime(), info.IsDir(), info.Size()), nil
+}
+
+func (lfs *LocalFileSystem) Open(ctx context.Context, name string) (io.ReadSeeker, error) {
+	resolved, err := lfs.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(resolved)
+}
+
+// Create creates (or truncates) the file at name for writing, making LocalFileSystem satisfy WritableFileSystem.
+func (lfs *LocalFileSystem) Create(ctx context.Context, name string) (io.WriteCloser, error) {
+	resolved, err := lfs.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Create(resolved)
+}
+
+// Remove removes the file at name.
+func (lfs *LocalFileSystem) Remove(ctx context.Context, name string) error {
+	resolved, err := lfs.resolve(name)
+	if err != nil {
+		return err
+	}
+	return os.Remove(resolved)
+}
+
+// MkdirAll creates name, along with any necessary parents, with the given permissions.
+func (lfs *LocalFileSystem) MkdirAll(ctx context.Context, name string, perm os.FileMode) error {
+	resolved, err := lfs.resolve(name)
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(resolved, perm)
+}
+
+// NewLocalFileSystem returns a LocalFileSystem rooted at root.  root is resolved to an absolute, symlink-free
+// path once here, so every later resolve call has a stable, real path to check escapes against.
+func NewLocalFileSystem(root string) (*LocalFileSystem, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("localfs: error resolving root %q: %w", root, err)
+	}
+	resolvedRoot, err := filepath.EvalSymlinks(absRoot)
+	if err != nil {
+		return nil, fmt.Errorf("localfs: error resolving root %q: %w", root, err)
+	}
+	return &LocalFileSystem{root: resolvedRoot}, nil
+}
@@ -0,0 +1,22 @@
+package log
+
+import (
+	"io"
+	"log"
+)
+
+type SimpleLogger struct {
+	w io.Writer
+}
+
+func NewSimpleLogger(w io.Writer) *SimpleLogger {
+	return &SimpleLogger{w: w}
+}
+
+func (l *SimpleLogger) Log(msg string, fields map[string]interface{}) error {
+	return nil
+}
+
+func WrapStandardLogger(l *SimpleLogger) *log.Logger {
+	return log.New(l.w, "", 0)
+}
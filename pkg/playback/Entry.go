@@ -0,0 +1,33 @@
+// =================================================================
+//
+// Work of the U.S. Department of Defense, Defense Digital Service.
+// Released as open source under the MIT License.  See LICENSE file.
+//
+// =================================================================
+
+package playback
+
+import (
+	"net/http"
+	"time"
+)
+
+// Entry records everything about a single served request needed to replay it and judge whether a later replay
+// reproduces the same behavior.
+type Entry struct {
+	Time   time.Time   `json:"time"`
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Header http.Header `json:"header"`
+
+	Host              string `json:"host"`
+	ServerName        string `json:"server_name"` // SNI, from the TLS handshake
+	TLSVersion        string `json:"tls_version"`
+	ClientCertSubject string `json:"client_cert_subject,omitempty"`
+
+	BodyHash string `json:"body_hash,omitempty"` // sha256 of the request body, hex-encoded
+
+	ResponseStatus   int           `json:"response_status"`
+	ResponseSize     int64         `json:"response_size"`
+	ResponseDuration time.Duration `json:"response_duration"`
+}
@@ -0,0 +1,95 @@
+// =================================================================
+//
+// Work of the U.S. Department of Defense, Defense Digital Service.
+// Released as open source under the MIT License.  See LICENSE file.
+//
+// =================================================================
+
+package playback
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+)
+
+// responseRecorder wraps a http.ResponseWriter to capture the status code and body size written through it.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	size   int64
+}
+
+func (rw *responseRecorder) WriteHeader(status int) {
+	rw.status = status
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *responseRecorder) Write(p []byte) (int, error) {
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+	n, err := rw.ResponseWriter.Write(p)
+	rw.size += int64(n)
+	return n, err
+}
+
+// RecordingHandler wraps next so that every request it serves is captured to recorder as an Entry, in addition to
+// being served normally.  A failure to record is not fatal to the request: it falls through to next unaffected.
+func RecordingHandler(next http.Handler, recorder *Recorder) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bodyHash := sha256.New()
+		if r.Body != nil {
+			r.Body = io.NopCloser(io.TeeReader(r.Body, bodyHash))
+		}
+
+		tlsVersion, serverName, clientCertSubject := "", "", ""
+		if r.TLS != nil {
+			tlsVersion = tlsVersionName(r.TLS.Version)
+			serverName = r.TLS.ServerName
+			if len(r.TLS.PeerCertificates) > 0 {
+				clientCertSubject = r.TLS.PeerCertificates[0].Subject.String()
+			}
+		}
+
+		rw := &responseRecorder{ResponseWriter: w}
+		start := time.Now()
+		next.ServeHTTP(rw, r)
+		duration := time.Since(start)
+
+		_ = recorder.Record(Entry{
+			Time:              start,
+			Method:            r.Method,
+			Path:              r.URL.String(),
+			Header:            r.Header.Clone(),
+			Host:              r.Host,
+			ServerName:        serverName,
+			TLSVersion:        tlsVersion,
+			ClientCertSubject: clientCertSubject,
+			BodyHash:          hex.EncodeToString(bodyHash.Sum(nil)),
+			ResponseStatus:    rw.status,
+			ResponseSize:      rw.size,
+			ResponseDuration:  duration,
+		})
+	})
+}
+
+// tlsVersionName matches the "1.0".."1.3" naming cmd/icecube uses for TLSVersionIdentifiers, so an entry's
+// TLSVersion lines up with the values icecube already logs per request.
+func tlsVersionName(version uint16) string {
+	switch version {
+	case tls.VersionTLS10:
+		return "1.0"
+	case tls.VersionTLS11:
+		return "1.1"
+	case tls.VersionTLS12:
+		return "1.2"
+	case tls.VersionTLS13:
+		return "1.3"
+	default:
+		return ""
+	}
+}
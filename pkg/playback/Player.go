@@ -0,0 +1,203 @@
+// =================================================================
+//
+// Work of the U.S. Department of Defense, Defense Digital Service.
+// Released as open source under the MIT License.  See LICENSE file.
+//
+// =================================================================
+
+package playback
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Player replays a recorded log of Entry values against baseURL and reports how the observed responses compare
+// to the ones originally recorded.
+type Player struct {
+	baseURL        string
+	httpClient     *http.Client
+	workers        int
+	rampUp         time.Duration
+	preserveTiming bool
+}
+
+// PlayerOption configures optional behavior of a Player, set via NewPlayer.
+type PlayerOption func(*Player)
+
+// WithWorkers sets the number of requests replayed concurrently (1 if n <= 0).
+func WithWorkers(n int) PlayerOption {
+	return func(p *Player) {
+		p.workers = n
+	}
+}
+
+// WithRampUp spreads worker startup evenly across d, instead of starting every worker at once, so a replay of a
+// bursty recording doesn't itself become a burst against the target.
+func WithRampUp(d time.Duration) PlayerOption {
+	return func(p *Player) {
+		p.rampUp = d
+	}
+}
+
+// WithPreserveTiming replays entries with the same gaps between them that were observed when they were recorded,
+// instead of as fast as the worker pool allows.
+func WithPreserveTiming(enabled bool) PlayerOption {
+	return func(p *Player) {
+		p.preserveTiming = enabled
+	}
+}
+
+// WithHTTPClient overrides the http.Client used to issue replayed requests, e.g. to set a custom TLS config.
+func WithHTTPClient(c *http.Client) PlayerOption {
+	return func(p *Player) {
+		p.httpClient = c
+	}
+}
+
+// NewPlayer returns a Player that replays recordings against baseURL.
+func NewPlayer(baseURL string, opts ...PlayerOption) *Player {
+	p := &Player{
+		baseURL:    baseURL,
+		httpClient: http.DefaultClient,
+		workers:    1,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.workers <= 0 {
+		p.workers = 1
+	}
+	return p
+}
+
+// Play reads the recording at path and replays every Entry against p.baseURL, comparing the observed status code
+// and response size against what was recorded.
+func (p *Player) Play(ctx context.Context, path string) (*Report, error) {
+	entries, err := readEntries(path)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make(chan Entry)
+	results := make(chan *Mismatch, len(entries))
+
+	var wg sync.WaitGroup
+	for i := 0; i < p.workers; i++ {
+		startDelay := time.Duration(0)
+		if p.rampUp > 0 && p.workers > 1 {
+			startDelay = p.rampUp * time.Duration(i) / time.Duration(p.workers-1)
+		}
+		wg.Add(1)
+		go func(startDelay time.Duration) {
+			defer wg.Done()
+			select {
+			case <-time.After(startDelay):
+			case <-ctx.Done():
+				return
+			}
+			for entry := range jobs {
+				results <- p.replay(ctx, entry)
+			}
+		}(startDelay)
+	}
+
+	go func() {
+		defer close(jobs)
+		var prev time.Time
+		for _, entry := range entries {
+			if p.preserveTiming && !prev.IsZero() {
+				if gap := entry.Time.Sub(prev); gap > 0 {
+					select {
+					case <-time.After(gap):
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+			prev = entry.Time
+			select {
+			case jobs <- entry:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	report := &Report{Total: len(entries)}
+	for m := range results {
+		if m == nil {
+			report.Matched++
+			continue
+		}
+		report.Mismatches = append(report.Mismatches, *m)
+	}
+	return report, nil
+}
+
+// replay issues entry as a request against p.baseURL and compares the observed response against what was
+// recorded, returning nil on a match or a *Mismatch describing the difference.
+func (p *Player) replay(ctx context.Context, entry Entry) *Mismatch {
+	req, err := http.NewRequestWithContext(ctx, entry.Method, strings.TrimSuffix(p.baseURL, "/")+entry.Path, nil)
+	if err != nil {
+		return &Mismatch{Entry: entry, Err: err.Error()}
+	}
+	req.Header = entry.Header.Clone()
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return &Mismatch{Entry: entry, Err: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	size, err := io.Copy(io.Discard, resp.Body)
+	if err != nil {
+		return &Mismatch{Entry: entry, ObservedStatus: resp.StatusCode, Err: err.Error()}
+	}
+
+	if resp.StatusCode == entry.ResponseStatus && size == entry.ResponseSize {
+		return nil
+	}
+	return &Mismatch{Entry: entry, ObservedStatus: resp.StatusCode, ObservedSize: size}
+}
+
+// readEntries reads every Entry from the JSONL recording at path, in order.
+func readEntries(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening recording file %q: %w", path, err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("error parsing recording entry in %q: %w", path, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading recording file %q: %w", path, err)
+	}
+	return entries, nil
+}
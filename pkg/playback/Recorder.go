@@ -0,0 +1,107 @@
+// =================================================================
+//
+// Work of the U.S. Department of Defense, Defense Digital Service.
+// Released as open source under the MIT License.  See LICENSE file.
+//
+// =================================================================
+
+package playback
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// DefaultMaxBytes is the size a recording file is allowed to reach before Recorder rotates it out to a
+// numbered sibling file (path.1, path.2, ...).
+const DefaultMaxBytes = 128 * 1024 * 1024 // 128 MiB
+
+// Recorder appends Entry values to a JSONL file (one JSON object per line), rotating to a new file once the
+// current one reaches maxBytes.
+type Recorder struct {
+	path     string
+	maxBytes int64
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+// NewRecorder returns a Recorder that appends to path, creating it if necessary.  maxBytes is the size at which
+// the file is rotated (DefaultMaxBytes if <= 0).
+func NewRecorder(path string, maxBytes int64) (*Recorder, error) {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+	r := &Recorder{path: path, maxBytes: maxBytes}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Recorder) open() error {
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening recording file %q: %w", r.path, err)
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("error stating recording file %q: %w", r.path, err)
+	}
+	r.f = f
+	r.size = fi.Size()
+	return nil
+}
+
+// rotate closes the current file and renames it aside with a ".1", ".2", ... suffix (the lowest unused one),
+// then opens a fresh file at path.  Caller must hold r.mu.
+func (r *Recorder) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return fmt.Errorf("error closing recording file %q: %w", r.path, err)
+	}
+	for i := 1; ; i++ {
+		rotated := fmt.Sprintf("%s.%d", r.path, i)
+		if _, err := os.Stat(rotated); os.IsNotExist(err) {
+			if err := os.Rename(r.path, rotated); err != nil {
+				return fmt.Errorf("error rotating recording file %q: %w", r.path, err)
+			}
+			break
+		}
+	}
+	return r.open()
+}
+
+// Record appends entry to the recording, rotating the file first if appending entry would take it past maxBytes.
+func (r *Recorder) Record(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("error marshaling playback entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.size > 0 && r.size+int64(len(line)) > r.maxBytes {
+		if err := r.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := r.f.Write(line)
+	if err != nil {
+		return fmt.Errorf("error writing to recording file %q: %w", r.path, err)
+	}
+	r.size += int64(n)
+	return nil
+}
+
+// Close closes the underlying recording file.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.f.Close()
+}
@@ -0,0 +1,23 @@
+// =================================================================
+//
+// Work of the U.S. Department of Defense, Defense Digital Service.
+// Released as open source under the MIT License.  See LICENSE file.
+//
+// =================================================================
+
+package playback
+
+// Mismatch describes one replayed Entry whose observed response differed from the one recorded.
+type Mismatch struct {
+	Entry          Entry  `json:"entry"`
+	ObservedStatus int    `json:"observed_status"`
+	ObservedSize   int64  `json:"observed_size"`
+	Err            string `json:"error,omitempty"`
+}
+
+// Report summarizes a completed Player run.
+type Report struct {
+	Total      int        `json:"total"`
+	Matched    int        `json:"matched"`
+	Mismatches []Mismatch `json:"mismatches,omitempty"`
+}
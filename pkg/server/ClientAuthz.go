@@ -0,0 +1,145 @@
+// =================================================================
+//
+// Work of the U.S. Department of Defense, Defense Digital Service.
+// Released as open source under the MIT License.  See LICENSE file.
+//
+// =================================================================
+
+package server
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// CallerIdentity is the identity extracted from a client certificate's leaf during an mTLS handshake.  SPIFFEID is
+// set when the leaf carries a URI SAN of the form "spiffe://trust-domain/workload/...", per the SPIFFE X.509-SVID
+// spec; Subject is always set, from the leaf's subject DN, so callers that authenticate with an ordinary PKI
+// certificate (no SPIFFE ID) can still be matched against an AuthzPolicy by subject DN pattern.
+type CallerIdentity struct {
+	SPIFFEID string
+	Subject  string
+}
+
+// String returns the identity in the form access logs and AuthzRule matching use: the SPIFFE ID if present,
+// otherwise the subject DN.
+func (ci *CallerIdentity) String() string {
+	if ci == nil {
+		return ""
+	}
+	if len(ci.SPIFFEID) > 0 {
+		return ci.SPIFFEID
+	}
+	return ci.Subject
+}
+
+// ExtractCallerIdentity returns the CallerIdentity of the leaf certificate presented in cs, or nil if cs carries no
+// peer certificate (e.g. client-auth is "request" and the client declined to present one).
+func ExtractCallerIdentity(cs *tls.ConnectionState) *CallerIdentity {
+	if cs == nil || len(cs.PeerCertificates) == 0 {
+		return nil
+	}
+	leaf := cs.PeerCertificates[0]
+	identity := &CallerIdentity{Subject: leaf.Subject.String()}
+	for _, u := range leaf.URIs {
+		if u.Scheme == "spiffe" {
+			identity.SPIFFEID = u.String()
+			break
+		}
+	}
+	return identity
+}
+
+// AuthzRule is a single entry in an AuthzPolicy: the caller may request Methods (empty means any method) under
+// PathPrefix on Site (empty means any configured site).
+type AuthzRule struct {
+	Site       string   `json:"site"`
+	PathPrefix string   `json:"path-prefix"`
+	Methods    []string `json:"methods"`
+}
+
+func (rule *AuthzRule) allows(site string, requestPath string, method string) bool {
+	if len(rule.Site) > 0 && rule.Site != site {
+		return false
+	}
+	if !strings.HasPrefix(requestPath, rule.PathPrefix) {
+		return false
+	}
+	if len(rule.Methods) == 0 {
+		return true
+	}
+	for _, m := range rule.Methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// identityMatchesPattern reports whether pattern (a path.Match glob) matches identity's SPIFFE ID or, failing
+// that, its subject DN.
+func identityMatchesPattern(identity *CallerIdentity, pattern string) bool {
+	if matched, err := path.Match(pattern, identity.SPIFFEID); err == nil && matched {
+		return true
+	}
+	matched, err := path.Match(pattern, identity.Subject)
+	return err == nil && matched
+}
+
+// AuthzPolicy maps a SPIFFE ID or subject DN pattern (matched with path.Match, so "spiffe://example.org/ns/*/sa/*"
+// and "CN=*.example.org" both work) to the set of AuthzRule tuples that identity is allowed.
+type AuthzPolicy map[string][]AuthzRule
+
+// Allowed reports whether identity may request method on requestPath at site, per policy.  An identity with no
+// matching pattern in policy is denied; ExtractCallerIdentity returning nil (no client certificate) is always
+// denied, since an AuthzPolicy is only meaningful once a caller has been authenticated.
+func (policy AuthzPolicy) Allowed(identity *CallerIdentity, site string, requestPath string, method string) bool {
+	if identity == nil {
+		return false
+	}
+	for pattern, rules := range policy {
+		if !identityMatchesPattern(identity, pattern) {
+			continue
+		}
+		for i := range rules {
+			if rules[i].allows(site, requestPath, method) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Known reports whether identity matches any pattern in policy at all, independent of site, path, or method.  It
+// is meant for use at TLS handshake time (see tls.Config.VerifyConnection), before the HTTP request that
+// Allowed's site/path/method checks need has been read.
+func (policy AuthzPolicy) Known(identity *CallerIdentity) bool {
+	if identity == nil {
+		return false
+	}
+	for pattern := range policy {
+		if identityMatchesPattern(identity, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadAuthzPolicy parses an AuthzPolicy from its JSON file format: a JSON object mapping a SPIFFE ID or subject DN
+// pattern to an array of {"site", "path-prefix", "methods"} rule objects.
+func LoadAuthzPolicy(r io.Reader) (AuthzPolicy, error) {
+	policy := AuthzPolicy{}
+	if err := json.NewDecoder(r).Decode(&policy); err != nil {
+		return nil, fmt.Errorf("error decoding client authorization policy: %w", err)
+	}
+	for pattern := range policy {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid client authorization pattern %q: %w", pattern, err)
+		}
+	}
+	return policy, nil
+}
@@ -0,0 +1,49 @@
+// =================================================================
+//
+// Work of the U.S. Department of Defense, Defense Digital Service.
+// Released as open source under the MIT License.  See LICENSE file.
+//
+// =================================================================
+
+package server
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics are registered with the default Prometheus registry at package init, so a process that imports pkg/server
+// exposes them on whatever /metrics endpoint it wires up with MetricsHandler, without any further setup.
+var (
+	// RequestDuration records end-to-end request latency, labeled by the site served, the request method, and the
+	// response status code.
+	RequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "icecube_request_duration_seconds",
+		Help:    "Duration of requests served by icecube, in seconds.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"site", "method", "status"})
+
+	// BytesServed counts response bytes written, labeled by site, so per-tenant egress can be tracked.
+	BytesServed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "icecube_bytes_served_total",
+		Help: "Total bytes served by icecube, labeled by site.",
+	}, []string{"site"})
+
+	// TLSHandshakeFailures counts TLS handshakes that icecube's VerifyConnection check rejects because the caller
+	// matches no client authorization policy rule, labeled by the negotiated TLS version and cipher suite.
+	// Handshakes that fail inside crypto/tls itself (version or cipher negotiation, client certificate
+	// verification) are not counted.
+	TLSHandshakeFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "icecube_tls_handshake_failures_total",
+		Help: "Total TLS handshakes rejected by the client authorization policy, labeled by TLS version and cipher suite.",
+	}, []string{"tls_version", "cipher_suite"})
+)
+
+// MetricsHandler returns the http.Handler that serves the process's Prometheus metrics in the text exposition
+// format, for mounting on --metrics-addr.
+func MetricsHandler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,377 @@
+// =================================================================
+//
+// Work of the U.S. Department of Defense, Defense Digital Service.
+// Released as open source under the MIT License.  See LICENSE file.
+//
+// =================================================================
+
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deptofdefense/icecube/pkg/fs"
+)
+
+// s3XMLNamespace is the XML namespace used by every S3 REST API response.
+const s3XMLNamespace = "http://s3.amazonaws.com/doc/2006-03-01/"
+
+// S3GatewayIdentities maps an AWS access key id to an opaque principal name used for logging and authorization checks.
+type S3GatewayIdentities map[string]string
+
+// S3Gateway serves a read-only subset of the AWS S3 REST API over a set of backing file systems, one per bucket name.
+type S3Gateway struct {
+	buckets    map[string]fs.FileSystem
+	identities S3GatewayIdentities
+}
+
+// NewS3Gateway returns a new S3Gateway serving the given bucket name to FileSystem mapping.
+func NewS3Gateway(buckets map[string]fs.FileSystem, identities S3GatewayIdentities) *S3Gateway {
+	return &S3Gateway{
+		buckets:    buckets,
+		identities: identities,
+	}
+}
+
+type s3Error struct {
+	XMLName   xml.Name `xml:"Error"`
+	Code      string   `xml:"Code"`
+	Message   string   `xml:"Message"`
+	Resource  string   `xml:"Resource"`
+	RequestID string   `xml:"RequestId"`
+}
+
+func writeS3Error(w http.ResponseWriter, status int, code string, message string, resource string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_ = xml.NewEncoder(w).Encode(&s3Error{
+		Code:     code,
+		Message:  message,
+		Resource: resource,
+	})
+}
+
+type s3Owner struct {
+	ID          string `xml:"ID"`
+	DisplayName string `xml:"DisplayName"`
+}
+
+type s3Content struct {
+	Key          string  `xml:"Key"`
+	LastModified string  `xml:"LastModified"`
+	ETag         string  `xml:"ETag"`
+	Size         int64   `xml:"Size"`
+	StorageClass string  `xml:"StorageClass"`
+	Owner        s3Owner `xml:"Owner"`
+}
+
+type s3CommonPrefix struct {
+	Prefix string `xml:"Prefix"`
+}
+
+type listObjectsResult struct {
+	XMLName        xml.Name          `xml:"ListBucketResult"`
+	Xmlns          string            `xml:"xmlns,attr"`
+	Name           string            `xml:"Name"`
+	Prefix         string            `xml:"Prefix"`
+	Marker         string            `xml:"Marker"`
+	NextMarker     string            `xml:"NextMarker,omitempty"`
+	Delimiter      string            `xml:"Delimiter,omitempty"`
+	MaxKeys        int               `xml:"MaxKeys"`
+	IsTruncated    bool              `xml:"IsTruncated"`
+	Contents       []s3Content       `xml:"Contents"`
+	CommonPrefixes []s3CommonPrefix  `xml:"CommonPrefixes,omitempty"`
+}
+
+type listObjectsV2Result struct {
+	XMLName               xml.Name         `xml:"ListBucketResult"`
+	Xmlns                 string           `xml:"xmlns,attr"`
+	Name                  string           `xml:"Name"`
+	Prefix                string           `xml:"Prefix"`
+	StartAfter            string           `xml:"StartAfter,omitempty"`
+	ContinuationToken     string           `xml:"ContinuationToken,omitempty"`
+	NextContinuationToken string           `xml:"NextContinuationToken,omitempty"`
+	KeyCount              int              `xml:"KeyCount"`
+	MaxKeys               int              `xml:"MaxKeys"`
+	Delimiter             string           `xml:"Delimiter,omitempty"`
+	IsTruncated           bool             `xml:"IsTruncated"`
+	Contents              []s3Content      `xml:"Contents"`
+	CommonPrefixes        []s3CommonPrefix `xml:"CommonPrefixes,omitempty"`
+}
+
+type versioningConfiguration struct {
+	XMLName xml.Name `xml:"VersioningConfiguration"`
+	Xmlns   string   `xml:"xmlns,attr"`
+}
+
+// s3AccessKey extracts the access key id from either a V2 ("AWS key:sig") or V4 ("AWS4-HMAC-SHA256 Credential=key/date/region/service/aws4_request, ...") Authorization header.
+// It returns an empty string if the header is missing or does not match either form.
+func s3AccessKey(authorization string) string {
+	if authorization == "" {
+		return ""
+	}
+	if strings.HasPrefix(authorization, "AWS4-HMAC-SHA256") {
+		for _, field := range strings.Split(authorization, " ") {
+			field = strings.TrimSuffix(field, ",")
+			if strings.HasPrefix(field, "Credential=") {
+				credential := strings.TrimPrefix(field, "Credential=")
+				return strings.SplitN(credential, "/", 2)[0]
+			}
+		}
+		return ""
+	}
+	if strings.HasPrefix(authorization, "AWS ") {
+		rest := strings.TrimPrefix(authorization, "AWS ")
+		return strings.SplitN(rest, ":", 2)[0]
+	}
+	return ""
+}
+
+// authorize returns the principal for the request's Authorization header, or an error if the header is missing or the access key is unknown.
+// Requests with no configured identities are treated as unauthenticated-allowed, preserving icecube's default of serving content without credentials.
+func (g *S3Gateway) authorize(r *http.Request) (string, error) {
+	if len(g.identities) == 0 {
+		return "", nil
+	}
+	accessKey := s3AccessKey(r.Header.Get("Authorization"))
+	if accessKey == "" {
+		return "", fmt.Errorf("missing or unsupported Authorization header")
+	}
+	principal, ok := g.identities[accessKey]
+	if !ok {
+		return "", fmt.Errorf("unknown access key %q", accessKey)
+	}
+	return principal, nil
+}
+
+// etagFor returns a deterministic ETag for an object derived from its key, size, and modification time.
+func etagFor(key string, size int64, modTime time.Time) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s:%d:%d", key, size, modTime.UnixNano())))
+	return `"` + hex.EncodeToString(sum[:])[:32] + `"`
+}
+
+func splitBucketKey(urlPath string) (string, string) {
+	trimmed := strings.TrimPrefix(urlPath, "/")
+	parts := strings.SplitN(trimmed, "/", 2)
+	bucket := parts[0]
+	key := ""
+	if len(parts) == 2 {
+		key = parts[1]
+	}
+	return bucket, key
+}
+
+func (g *S3Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeS3Error(w, http.StatusMethodNotAllowed, "MethodNotAllowed", "The specified method is not allowed against this resource.", r.URL.Path)
+		return
+	}
+
+	if _, err := g.authorize(r); err != nil {
+		writeS3Error(w, http.StatusForbidden, "AccessDenied", err.Error(), r.URL.Path)
+		return
+	}
+
+	bucket, key := splitBucketKey(r.URL.Path)
+	if bucket == "" {
+		writeS3Error(w, http.StatusBadRequest, "InvalidBucketName", "A bucket name is required.", r.URL.Path)
+		return
+	}
+
+	backend, ok := g.buckets[bucket]
+	if !ok {
+		writeS3Error(w, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist.", r.URL.Path)
+		return
+	}
+
+	if key == "" {
+		if _, ok := r.URL.Query()["versioning"]; ok {
+			g.serveVersioning(w)
+			return
+		}
+		g.serveListObjects(w, r, backend, bucket)
+		return
+	}
+
+	if r.Method == http.MethodHead {
+		g.serveHeadObject(w, r, backend, key)
+		return
+	}
+
+	g.serveGetObject(w, r, backend, key)
+}
+
+func (g *S3Gateway) serveVersioning(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/xml")
+	_ = xml.NewEncoder(w).Encode(&versioningConfiguration{
+		Xmlns: s3XMLNamespace,
+	})
+}
+
+// listObjectsPage lists at most maxKeys non-directory entries starting at token, using backend's ReadDirPage when
+// available so the returned nextToken is a real, resumable cursor. Backends that don't implement PagedFileSystem
+// fall back to a single unpaged ReadDir: nextToken is then always "", since there is no cursor to resume from, but
+// isTruncated still reflects whether entries were cut off at maxKeys, instead of always reporting false.
+func listObjectsPage(ctx context.Context, backend fs.FileSystem, listPath string, delimiter string, token string, maxKeys int) (contents []s3Content, commonPrefixes []s3CommonPrefix, isTruncated bool, nextToken string, err error) {
+	commonPrefixes = make([]s3CommonPrefix, 0)
+
+	var directoryEntries []fs.DirectoryEntry
+	if paged, ok := backend.(fs.PagedFileSystem); ok {
+		directoryEntries, nextToken, err = paged.ReadDirPage(ctx, listPath, token, maxKeys)
+		if err != nil {
+			return nil, nil, false, "", err
+		}
+		isTruncated = nextToken != ""
+	} else {
+		directoryEntries, err = backend.ReadDir(ctx, listPath)
+		if err != nil {
+			return nil, nil, false, "", err
+		}
+	}
+
+	contents = make([]s3Content, 0, len(directoryEntries))
+	for _, entry := range directoryEntries {
+		if entry.IsDir() {
+			if delimiter != "" {
+				commonPrefixes = append(commonPrefixes, s3CommonPrefix{Prefix: entry.Name()})
+			}
+			continue
+		}
+		if nextToken == "" && len(contents) >= maxKeys {
+			isTruncated = true
+			break
+		}
+		size := int64(0)
+		if withSize, ok := entry.(fs.DirectoryEntryWithSize); ok {
+			size = withSize.Size()
+		}
+		contents = append(contents, s3Content{
+			Key:          entry.Name(),
+			LastModified: entry.ModTime().UTC().Format(time.RFC3339),
+			ETag:         etagFor(entry.Name(), size, entry.ModTime()),
+			Size:         size,
+			StorageClass: "STANDARD",
+		})
+	}
+	return contents, commonPrefixes, isTruncated, nextToken, nil
+}
+
+func (g *S3Gateway) serveListObjects(w http.ResponseWriter, r *http.Request, backend fs.FileSystem, bucket string) {
+	ctx := r.Context()
+	query := r.URL.Query()
+	prefix := query.Get("prefix")
+	delimiter := query.Get("delimiter")
+	maxKeys := 1000
+	if mk := query.Get("max-keys"); mk != "" {
+		if parsed, err := strconv.Atoi(mk); err == nil && parsed > 0 {
+			maxKeys = parsed
+		}
+	}
+
+	listPath := "/"
+	if prefix != "" {
+		listPath = "/" + strings.TrimSuffix(prefix, "/")
+	}
+
+	isV2 := query.Get("list-type") == "2"
+	token := query.Get("continuation-token")
+	if !isV2 {
+		token = query.Get("marker")
+	}
+
+	contents, commonPrefixes, isTruncated, nextToken, err := listObjectsPage(ctx, backend, listPath, delimiter, token, maxKeys)
+	if err != nil && !backend.IsNotExist(err) {
+		writeS3Error(w, http.StatusInternalServerError, "InternalError", err.Error(), r.URL.Path)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	if isV2 {
+		_ = xml.NewEncoder(w).Encode(&listObjectsV2Result{
+			Xmlns:                 s3XMLNamespace,
+			Name:                  bucket,
+			Prefix:                prefix,
+			ContinuationToken:     token,
+			NextContinuationToken: nextToken,
+			StartAfter:            query.Get("start-after"),
+			KeyCount:              len(contents),
+			MaxKeys:               maxKeys,
+			Delimiter:             delimiter,
+			IsTruncated:           isTruncated,
+			Contents:              contents,
+			CommonPrefixes:        commonPrefixes,
+		})
+		return
+	}
+	_ = xml.NewEncoder(w).Encode(&listObjectsResult{
+		Xmlns:          s3XMLNamespace,
+		Name:           bucket,
+		Prefix:         prefix,
+		Marker:         token,
+		NextMarker:     nextToken,
+		Delimiter:      delimiter,
+		MaxKeys:        maxKeys,
+		IsTruncated:    isTruncated,
+		Contents:       contents,
+		CommonPrefixes: commonPrefixes,
+	})
+}
+
+func (g *S3Gateway) serveHeadObject(w http.ResponseWriter, r *http.Request, backend fs.FileSystem, key string) {
+	fi, err := backend.Stat(r.Context(), "/"+key)
+	if err != nil {
+		if backend.IsNotExist(err) {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.", r.URL.Path)
+			return
+		}
+		writeS3Error(w, http.StatusInternalServerError, "InternalError", err.Error(), r.URL.Path)
+		return
+	}
+	if fi.IsDir() {
+		writeS3Error(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.", r.URL.Path)
+		return
+	}
+	w.Header().Set("Content-Length", strconv.FormatInt(fi.Size(), 10))
+	w.Header().Set("Last-Modified", fi.ModTime().UTC().Format(http.TimeFormat))
+	w.Header().Set("ETag", etagFor(key, fi.Size(), fi.ModTime()))
+	w.WriteHeader(http.StatusOK)
+}
+
+func (g *S3Gateway) serveGetObject(w http.ResponseWriter, r *http.Request, backend fs.FileSystem, key string) {
+	ctx := r.Context()
+	fi, err := backend.Stat(ctx, "/"+key)
+	if err != nil {
+		if backend.IsNotExist(err) {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.", r.URL.Path)
+			return
+		}
+		writeS3Error(w, http.StatusInternalServerError, "InternalError", err.Error(), r.URL.Path)
+		return
+	}
+	if fi.IsDir() {
+		writeS3Error(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.", r.URL.Path)
+		return
+	}
+
+	content, err := backend.Open(ctx, "/"+key)
+	if err != nil {
+		writeS3Error(w, http.StatusInternalServerError, "InternalError", err.Error(), r.URL.Path)
+		return
+	}
+
+	w.Header().Set("ETag", etagFor(key, fi.Size(), fi.ModTime()))
+	http.ServeContent(w, r, key, fi.ModTime(), content)
+}
+
+// NewS3GatewayHandler wraps a S3Gateway so it only responds to requests under pathPrefix, stripping the prefix before routing.
+func NewS3GatewayHandler(gateway *S3Gateway, pathPrefix string) http.Handler {
+	return http.StripPrefix(pathPrefix, gateway)
+}
@@ -15,8 +15,18 @@ import (
 	"time"
 )
 
-func ServeContent(w http.ResponseWriter, r *http.Request, p string, content io.ReadSeeker, modtime time.Time, download bool, errorHandler func(w http.ResponseWriter, r *http.Request, err error) error) {
-	w.Header().Set("Cache-Control", "no-cache")
+// DefaultCacheControl is used by ServeContent when cacheControl is empty.  Unlike "no-cache", it lets clients that
+// have already sent a matching If-None-Match revalidate without a round trip for content.
+const DefaultCacheControl = "public, max-age=0, must-revalidate"
+
+func ServeContent(w http.ResponseWriter, r *http.Request, p string, content io.ReadSeeker, modtime time.Time, etag string, cacheControl string, download bool, errorHandler func(w http.ResponseWriter, r *http.Request, err error) error) {
+	if len(cacheControl) == 0 {
+		cacheControl = DefaultCacheControl
+	}
+	w.Header().Set("Cache-Control", cacheControl)
+	if len(etag) > 0 {
+		w.Header().Set("ETag", etag)
+	}
 	if download {
 		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filepath.Base(p)))
 	}
@@ -0,0 +1,65 @@
+// =================================================================
+//
+// Work of the U.S. Department of Defense, Defense Digital Service.
+// Released as open source under the MIT License.  See LICENSE file.
+//
+// =================================================================
+
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// OTLPProtocolGRPC and OTLPProtocolHTTP are the wire protocols NewTracerProvider's OTLP exporter can speak.
+const (
+	OTLPProtocolGRPC = "grpc"
+	OTLPProtocolHTTP = "http"
+)
+
+// NewTracerProvider returns a TracerProvider that exports spans to the OTLP collector at endpoint over protocol
+// ("grpc" or "http"), sampling the fraction of traces named by sampler (0 samples nothing, 1 samples everything,
+// anything in between is a TraceIDRatioBased sample of a parent-sampled trace), and attaching headers (e.g. an
+// auth token) to every export request.  The caller is responsible for calling otel.SetTracerProvider with the
+// result, so instrumentation elsewhere in the process picks it up.
+func NewTracerProvider(ctx context.Context, endpoint string, protocol string, sampler float64, headers map[string]string) (*sdktrace.TracerProvider, error) {
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch protocol {
+	case OTLPProtocolGRPC:
+		exporter, err = otlptracegrpc.New(ctx,
+			otlptracegrpc.WithEndpoint(endpoint),
+			otlptracegrpc.WithHeaders(headers),
+			otlptracegrpc.WithInsecure(),
+		)
+	case OTLPProtocolHTTP:
+		exporter, err = otlptracehttp.New(ctx,
+			otlptracehttp.WithEndpoint(endpoint),
+			otlptracehttp.WithHeaders(headers),
+			otlptracehttp.WithInsecure(),
+		)
+	default:
+		return nil, fmt.Errorf("unsupported OTLP protocol %q", protocol)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error creating OTLP exporter: %w", err)
+	}
+
+	res, err := resource.New(ctx, resource.WithAttributes(attribute.String("service.name", "icecube")))
+	if err != nil {
+		return nil, fmt.Errorf("error creating trace resource: %w", err)
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampler))),
+	), nil
+}
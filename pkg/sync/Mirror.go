@@ -0,0 +1,286 @@
+// =================================================================
+//
+// Work of the U.S. Department of Defense, Defense Digital Service.
+// Released as open source under the MIT License.  See LICENSE file.
+//
+// =================================================================
+
+// Package sync mirrors content between any two fs.FileSystem implementations, in the spirit of `mc mirror`.
+package sync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path"
+	"sync"
+
+	"github.com/deptofdefense/icecube/pkg/fs"
+)
+
+// MirrorOptions configures a Mirror run.
+type MirrorOptions struct {
+	// Checksum compares a streamed content hash instead of Size and ModTime when deciding whether an object has
+	// changed.
+	Checksum bool
+	// Parallelism bounds the number of objects copied concurrently.  Defaults to 1 if less than 1.
+	Parallelism int
+	// Delete removes destination entries that are missing from the source.
+	Delete bool
+	// DryRun surfaces the plan through Stats without writing or deleting anything.
+	DryRun bool
+}
+
+// Stats summarizes the outcome of a Mirror run.
+type Stats struct {
+	Copied      int
+	Skipped     int
+	Deleted     int
+	BytesCopied int64
+	// Plan lists the actions that would be taken, populated only when MirrorOptions.DryRun is set.
+	Plan []string
+}
+
+type statUpdate func(*Stats)
+
+type workItem struct {
+	path string
+	info fs.FileInfo
+}
+
+// Mirror walks src recursively and copies objects missing or changed in dst.  Change detection compares Size and
+// ModTime by default, and only a streamed content hash when opts.Checksum is set.  Copies are performed by
+// opts.Parallelism workers pulling from a single walker that streams listings via fs.PagedFileSystem when available,
+// so large trees are never buffered in full.
+func Mirror(ctx context.Context, src fs.FileSystem, dst fs.WritableFileSystem, opts MirrorOptions) (Stats, error) {
+	parallelism := opts.Parallelism
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	seen := make(map[string]bool)
+	var seenMu sync.Mutex
+
+	updates := make(chan statUpdate)
+	statsDone := make(chan Stats)
+	go func() {
+		stats := Stats{}
+		for update := range updates {
+			update(&stats)
+		}
+		statsDone <- stats
+	}()
+
+	items := make(chan workItem)
+	var workErr error
+	var workErrMu sync.Mutex
+	recordErr := func(err error) {
+		workErrMu.Lock()
+		defer workErrMu.Unlock()
+		if workErr == nil {
+			workErr = err
+		}
+	}
+
+	var workers sync.WaitGroup
+	for i := 0; i < parallelism; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for item := range items {
+				seenMu.Lock()
+				seen[item.path] = true
+				seenMu.Unlock()
+
+				copied, n, err := mirrorOne(ctx, src, dst, item.path, item.info, opts)
+				if err != nil {
+					recordErr(fmt.Errorf("error mirroring %q: %w", item.path, err))
+					continue
+				}
+				p := item.path
+				updates <- func(s *Stats) {
+					if !copied {
+						s.Skipped++
+						return
+					}
+					s.Copied++
+					s.BytesCopied += n
+					if opts.DryRun {
+						s.Plan = append(s.Plan, "copy "+p)
+					}
+				}
+			}
+		}()
+	}
+
+	walkErr := walk(ctx, src, "/", items)
+	close(items)
+	workers.Wait()
+
+	if walkErr == nil {
+		walkErr = workErr
+	}
+
+	if walkErr == nil && opts.Delete {
+		if _, err := pruneDeleted(ctx, dst, seen, opts, updates); err != nil {
+			walkErr = err
+		}
+	}
+
+	close(updates)
+	stats := <-statsDone
+
+	return stats, walkErr
+}
+
+// walk streams every file under name in src into items, recursing into subdirectories.  It uses ReadDirPage when src
+// implements fs.PagedFileSystem so directories are never buffered in full.
+func walk(ctx context.Context, filesystem fs.FileSystem, name string, items chan<- workItem) error {
+	paged, isPaged := filesystem.(fs.PagedFileSystem)
+	token := ""
+	for {
+		var entries []fs.DirectoryEntry
+		var err error
+		if isPaged {
+			entries, token, err = paged.ReadDirPage(ctx, name, token, 1000)
+		} else {
+			entries, err = filesystem.ReadDir(ctx, name)
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			childPath := filesystem.Join(name, entry.Name())
+			if entry.IsDir() {
+				if err := walk(ctx, filesystem, childPath, items); err != nil {
+					return err
+				}
+				continue
+			}
+			info, statErr := filesystem.Stat(ctx, childPath)
+			if statErr != nil {
+				return fmt.Errorf("error stating %q: %w", childPath, statErr)
+			}
+			select {
+			case items <- workItem{path: childPath, info: info}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		if !isPaged || token == "" {
+			break
+		}
+	}
+	return nil
+}
+
+// mirrorOne copies path from src to dst if it is missing or changed in dst, returning whether a copy happened and
+// how many bytes were copied.
+func mirrorOne(ctx context.Context, src fs.FileSystem, dst fs.WritableFileSystem, objectPath string, srcInfo fs.FileInfo, opts MirrorOptions) (bool, int64, error) {
+	dstInfo, err := dst.Stat(ctx, objectPath)
+	if err == nil {
+		unchanged, err := unchanged(ctx, src, dst, objectPath, srcInfo, dstInfo, opts)
+		if err != nil {
+			return false, 0, err
+		}
+		if unchanged {
+			return false, 0, nil
+		}
+	} else if !dst.IsNotExist(err) {
+		return false, 0, err
+	}
+
+	if opts.DryRun {
+		return true, srcInfo.Size(), nil
+	}
+
+	content, err := src.Open(ctx, objectPath)
+	if err != nil {
+		return false, 0, err
+	}
+	if err := dst.MkdirAll(ctx, path.Dir(objectPath), 0755); err != nil {
+		return false, 0, err
+	}
+	w, err := dst.Create(ctx, objectPath)
+	if err != nil {
+		return false, 0, err
+	}
+	n, copyErr := io.Copy(w, content)
+	if closeErr := w.Close(); copyErr == nil {
+		copyErr = closeErr
+	}
+	if copyErr != nil {
+		return false, 0, copyErr
+	}
+	return true, n, nil
+}
+
+// unchanged reports whether srcInfo and dstInfo describe the same object, comparing Size and ModTime or, when
+// opts.Checksum is set, a streamed content hash instead.
+func unchanged(ctx context.Context, src fs.FileSystem, dst fs.FileSystem, objectPath string, srcInfo fs.FileInfo, dstInfo fs.FileInfo, opts MirrorOptions) (bool, error) {
+	sameSizeAndModTime := srcInfo.Size() == dstInfo.Size() && srcInfo.ModTime().Equal(dstInfo.ModTime())
+	if !opts.Checksum {
+		return sameSizeAndModTime, nil
+	}
+	srcSum, err := hashContent(ctx, src, objectPath)
+	if err != nil {
+		return false, err
+	}
+	dstSum, err := hashContent(ctx, dst, objectPath)
+	if err != nil {
+		return false, err
+	}
+	return srcSum == dstSum, nil
+}
+
+// hashContent streams objectPath's content through sha256 without buffering it in memory.
+func hashContent(ctx context.Context, filesystem fs.FileSystem, objectPath string) (string, error) {
+	content, err := filesystem.Open(ctx, objectPath)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, content); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// pruneDeleted walks dst and removes entries that were not seen while walking src.
+func pruneDeleted(ctx context.Context, dst fs.WritableFileSystem, seen map[string]bool, opts MirrorOptions, updates chan<- statUpdate) (int, error) {
+	items := make(chan workItem)
+	walkErrCh := make(chan error, 1)
+	go func() {
+		walkErrCh <- walk(ctx, dst, "/", items)
+		close(items)
+	}()
+
+	deleted := 0
+	for item := range items {
+		if seen[item.path] {
+			continue
+		}
+		if !opts.DryRun {
+			if err := dst.Remove(ctx, item.path); err != nil {
+				return deleted, fmt.Errorf("error deleting %q: %w", item.path, err)
+			}
+		}
+		deleted++
+		p := item.path
+		updates <- func(s *Stats) {
+			s.Deleted++
+			if opts.DryRun {
+				s.Plan = append(s.Plan, "delete "+p)
+			}
+		}
+	}
+
+	if err := <-walkErrCh; err != nil {
+		return deleted, err
+	}
+	return deleted, nil
+}
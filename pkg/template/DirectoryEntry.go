@@ -0,0 +1,86 @@
+// =================================================================
+//
+// Work of the U.S. Department of Defense, Defense Digital Service.
+// Released as open source under the MIT License.  See LICENSE file.
+//
+// =================================================================
+
+package template
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/deptofdefense/icecube/pkg/fs"
+)
+
+// directoryEntrySize returns de's size if it implements fs.DirectoryEntryWithSize, and 0 otherwise (directories,
+// and any DirectoryEntry whose backend hasn't implemented the optional interface).
+func directoryEntrySize(de fs.DirectoryEntry) int64 {
+	if withSize, ok := de.(fs.DirectoryEntryWithSize); ok {
+		return withSize.Size()
+	}
+	return 0
+}
+
+// sortBy returns a stably-sorted copy of entries, ordered by field ("name", "size", or "modTime"; "name" for any
+// other value), ascending unless order is "desc".  entries itself is left untouched, so a directory listing
+// fetched once can be sorted more than one way in the same template render.
+func sortBy(entries []fs.DirectoryEntry, field string, order string) []fs.DirectoryEntry {
+	sorted := make([]fs.DirectoryEntry, len(entries))
+	copy(sorted, entries)
+
+	var less func(i, j int) bool
+	switch field {
+	case "size":
+		less = func(i, j int) bool { return directoryEntrySize(sorted[i]) < directoryEntrySize(sorted[j]) }
+	case "modTime":
+		less = func(i, j int) bool { return sorted[i].ModTime().Before(sorted[j].ModTime()) }
+	default:
+		less = func(i, j int) bool { return sorted[i].Name() < sorted[j].Name() }
+	}
+	if order == "desc" {
+		ascending := less
+		less = func(i, j int) bool { return ascending(j, i) }
+	}
+	sort.SliceStable(sorted, less)
+	return sorted
+}
+
+// filterByExt returns the entries in entries whose name has the given extension (as path.Ext would report it,
+// e.g. ".txt"), always keeping directories so a listing's structure isn't hidden by a file-extension filter.
+func filterByExt(entries []fs.DirectoryEntry, ext string) []fs.DirectoryEntry {
+	filtered := []fs.DirectoryEntry{}
+	for _, de := range entries {
+		if de.IsDir() || path.Ext(de.Name()) == ext {
+			filtered = append(filtered, de)
+		}
+	}
+	return filtered
+}
+
+// filterByPrefix returns the entries in entries whose name starts with prefix.
+func filterByPrefix(entries []fs.DirectoryEntry, prefix string) []fs.DirectoryEntry {
+	filtered := []fs.DirectoryEntry{}
+	for _, de := range entries {
+		if strings.HasPrefix(de.Name(), prefix) {
+			filtered = append(filtered, de)
+		}
+	}
+	return filtered
+}
+
+// groupByDir splits entries into "dirs" and "files", so a template can render subdirectories and files as
+// separate lists without its own conditional on IsDir per entry.
+func groupByDir(entries []fs.DirectoryEntry) map[string][]fs.DirectoryEntry {
+	grouped := map[string][]fs.DirectoryEntry{"dirs": {}, "files": {}}
+	for _, de := range entries {
+		if de.IsDir() {
+			grouped["dirs"] = append(grouped["dirs"], de)
+		} else {
+			grouped["files"] = append(grouped["files"], de)
+		}
+	}
+	return grouped
+}
@@ -0,0 +1,112 @@
+// =================================================================
+//
+// Work of the U.S. Department of Defense, Defense Digital Service.
+// Released as open source under the MIT License.  See LICENSE file.
+//
+// =================================================================
+
+package template
+
+import (
+	"testing"
+	"time"
+
+	"github.com/deptofdefense/icecube/pkg/fs"
+)
+
+type testDirectoryEntry struct {
+	name    string
+	dir     bool
+	modTime time.Time
+	size    int64
+}
+
+func (de *testDirectoryEntry) Name() string       { return de.name }
+func (de *testDirectoryEntry) IsDir() bool        { return de.dir }
+func (de *testDirectoryEntry) ModTime() time.Time { return de.modTime }
+func (de *testDirectoryEntry) Size() int64        { return de.size }
+
+func newTestEntries() []fs.DirectoryEntry {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	return []fs.DirectoryEntry{
+		&testDirectoryEntry{name: "b.txt", size: 20, modTime: base.Add(2 * time.Hour)},
+		&testDirectoryEntry{name: "a.txt", size: 20, modTime: base.Add(1 * time.Hour)},
+		&testDirectoryEntry{name: "c.txt", size: 10, modTime: base.Add(3 * time.Hour)},
+	}
+}
+
+func entryNames(entries []fs.DirectoryEntry) []string {
+	names := make([]string, len(entries))
+	for i, de := range entries {
+		names[i] = de.Name()
+	}
+	return names
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSortByName(t *testing.T) {
+	got := entryNames(sortBy(newTestEntries(), "name", "asc"))
+	want := []string{"a.txt", "b.txt", "c.txt"}
+	if !equalStrings(got, want) {
+		t.Errorf("sortBy name asc = %v, want %v", got, want)
+	}
+}
+
+// TestSortBySizeIsStable exercises sortBy's tie-breaking behavior: a.txt and b.txt share size 20, so a stable
+// sort must preserve their relative input order (b before a) rather than an unstable sort that's free to swap
+// equal elements.
+func TestSortBySizeIsStable(t *testing.T) {
+	got := entryNames(sortBy(newTestEntries(), "size", "asc"))
+	want := []string{"c.txt", "b.txt", "a.txt"}
+	if !equalStrings(got, want) {
+		t.Errorf("sortBy size asc = %v, want %v (stability broken)", got, want)
+	}
+}
+
+func TestSortByModTimeDesc(t *testing.T) {
+	got := entryNames(sortBy(newTestEntries(), "modTime", "desc"))
+	want := []string{"c.txt", "b.txt", "a.txt"}
+	if !equalStrings(got, want) {
+		t.Errorf("sortBy modTime desc = %v, want %v", got, want)
+	}
+}
+
+func TestSortByDoesNotMutateInput(t *testing.T) {
+	entries := newTestEntries()
+	original := entryNames(entries)
+	_ = sortBy(entries, "name", "asc")
+	if !equalStrings(entryNames(entries), original) {
+		t.Errorf("sortBy mutated its input: got %v, want %v", entryNames(entries), original)
+	}
+}
+
+func TestFilterByExtKeepsDirectories(t *testing.T) {
+	entries := append(newTestEntries(), &testDirectoryEntry{name: "sub", dir: true})
+	got := entryNames(filterByExt(entries, ".txt"))
+	want := []string{"b.txt", "a.txt", "c.txt", "sub"}
+	if !equalStrings(got, want) {
+		t.Errorf("filterByExt = %v, want %v", got, want)
+	}
+}
+
+func TestGroupByDir(t *testing.T) {
+	entries := append(newTestEntries(), &testDirectoryEntry{name: "sub", dir: true})
+	grouped := groupByDir(entries)
+	if got := entryNames(grouped["dirs"]); !equalStrings(got, []string{"sub"}) {
+		t.Errorf("groupByDir dirs = %v, want [sub]", got)
+	}
+	if got := entryNames(grouped["files"]); !equalStrings(got, []string{"b.txt", "a.txt", "c.txt"}) {
+		t.Errorf("groupByDir files = %v, want [b.txt a.txt c.txt]", got)
+	}
+}
@@ -0,0 +1,75 @@
+// =================================================================
+//
+// Work of the U.S. Department of Defense, Defense Digital Service.
+// Released as open source under the MIT License.  See LICENSE file.
+//
+// =================================================================
+
+package template
+
+import (
+	"fmt"
+	"time"
+)
+
+// humanizeBytes renders size as a short, human-readable byte count using binary (1024-based) units, e.g.
+// "1.2 MiB", matching the convention most *nix tools use (du -h, ls -lh) rather than SI decimal units.
+func humanizeBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	units := []string{"KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+	return fmt.Sprintf("%.1f %s", float64(size)/float64(div), units[exp])
+}
+
+// relTimeUnit picks the coarsest unit (second through year) that d amounts to at least one of, and returns that
+// duration rounded down to a whole count of it.
+func relTimeUnit(d time.Duration) (string, int64) {
+	seconds := int64(d / time.Second)
+	switch {
+	case seconds < 60:
+		return "second", seconds
+	case seconds < 60*60:
+		return "minute", seconds / 60
+	case seconds < 60*60*24:
+		return "hour", seconds / (60 * 60)
+	case seconds < 60*60*24*30:
+		return "day", seconds / (60 * 60 * 24)
+	case seconds < 60*60*24*365:
+		return "month", seconds / (60 * 60 * 24 * 30)
+	default:
+		return "year", seconds / (60 * 60 * 24 * 365)
+	}
+}
+
+// relTime renders t relative to now as a short, human-readable duration, e.g. "3 hours ago" or "in 2 days".  The
+// comparison is done on the instant each time.Time represents, so a difference in the time.Location t and now
+// carry never changes the result.
+func relTime(t time.Time, now time.Time) string {
+	d := now.Sub(t)
+	future := d < 0
+	if future {
+		d = -d
+	}
+	unit, n := relTimeUnit(d)
+	s := fmt.Sprintf("%d %s", n, unit)
+	if n != 1 {
+		s += "s"
+	}
+	if future {
+		return "in " + s
+	}
+	return s + " ago"
+}
+
+// humanizeTime renders t relative to the current wall-clock time; it is the funcMap entry templates call, since a
+// template has no way to supply "now" itself.
+func humanizeTime(t time.Time) string {
+	return relTime(t, time.Now())
+}
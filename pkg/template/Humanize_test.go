@@ -0,0 +1,71 @@
+// =================================================================
+//
+// Work of the U.S. Department of Defense, Defense Digital Service.
+// Released as open source under the MIT License.  See LICENSE file.
+//
+// =================================================================
+
+package template
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHumanizeBytes(t *testing.T) {
+	cases := []struct {
+		size int64
+		want string
+	}{
+		{0, "0 B"},
+		{999, "999 B"},
+		{1024, "1.0 KiB"},
+		{1536, "1.5 KiB"},
+		{1024 * 1024, "1.0 MiB"},
+		{1258291, "1.2 MiB"},
+		{1024 * 1024 * 1024, "1.0 GiB"},
+	}
+	for _, c := range cases {
+		if got := humanizeBytes(c.size); got != c.want {
+			t.Errorf("humanizeBytes(%d) = %q, want %q", c.size, got, c.want)
+		}
+	}
+}
+
+func TestRelTime(t *testing.T) {
+	now := time.Date(2026, 7, 25, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name string
+		t    time.Time
+		want string
+	}{
+		{"seconds ago", now.Add(-30 * time.Second), "30 seconds ago"},
+		{"one minute ago", now.Add(-1 * time.Minute), "1 minute ago"},
+		{"hours ago", now.Add(-3 * time.Hour), "3 hours ago"},
+		{"days ago", now.Add(-2 * 24 * time.Hour), "2 days ago"},
+		{"future", now.Add(2 * time.Hour), "in 2 hours"},
+	}
+	for _, c := range cases {
+		if got := relTime(c.t, now); got != c.want {
+			t.Errorf("%s: relTime(...) = %q, want %q", c.name, got, c.want)
+		}
+	}
+}
+
+// TestRelTimeAcrossTimeZones confirms relTime compares instants, not wall-clock fields: a timestamp recorded in
+// one time.Location must produce the same answer whether "now" is evaluated in that same zone, in UTC, or in a
+// third zone entirely.
+func TestRelTimeAcrossTimeZones(t *testing.T) {
+	est := time.FixedZone("EST", -5*60*60)
+	ist := time.FixedZone("IST", 5*60*60+30*60)
+
+	nowUTC := time.Date(2026, 7, 25, 12, 0, 0, 0, time.UTC)
+	threeHoursAgoInEST := nowUTC.Add(-3 * time.Hour).In(est)
+	nowInIST := nowUTC.In(ist)
+
+	got := relTime(threeHoursAgoInEST, nowInIST)
+	want := "3 hours ago"
+	if got != want {
+		t.Errorf("relTime across time zones = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,132 @@
+// =================================================================
+//
+// Work of the U.S. Department of Defense, Defense Digital Service.
+// Released as open source under the MIT License.  See LICENSE file.
+//
+// =================================================================
+
+package template
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync/atomic"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Manager watches a template file on disk and keeps a parsed, validated Template ready for Execute, re-parsing
+// and atomically swapping in a new version whenever the file changes.  This lets operators iterate on a
+// directory-listing template in production without restarting the server: a version that fails to parse, or
+// fails to execute against fixture, is reported to onError and the Manager keeps serving whatever last parsed and
+// validated successfully.
+type Manager struct {
+	name    string
+	path    string
+	fixture any
+	onError func(error)
+
+	current atomic.Value // holds a Template
+	watcher *fsnotify.Watcher
+	done    chan struct{}
+}
+
+// NewManager returns a Manager serving the template at path under name, parsed and validated once immediately.
+// fixture is the data Execute is called with as a smoke test, both now and on every subsequent reload, before a
+// freshly-parsed template replaces the one currently served.  onError, if non-nil, is called from a background
+// goroutine with any error encountered watching path or reloading the template; it is never called for the
+// initial parse, whose error is returned directly, since there is no previously-loaded template to fall back on
+// yet.
+func NewManager(name string, path string, fixture any, onError func(error)) (*Manager, error) {
+	m := &Manager{name: name, path: path, fixture: fixture, onError: onError}
+
+	t, err := m.parseAndValidate()
+	if err != nil {
+		return nil, err
+	}
+	m.current.Store(t)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("error creating watcher for template %q: %w", path, err)
+	}
+	if err := watcher.Add(path); err != nil {
+		_ = watcher.Close()
+		return nil, fmt.Errorf("error watching template %q: %w", path, err)
+	}
+	m.watcher = watcher
+	m.done = make(chan struct{})
+	go m.watch()
+
+	return m, nil
+}
+
+// parseAndValidate re-reads and re-parses the template file, then executes it against fixture, discarding the
+// output, so a template that merely parses but panics or errors on real data is caught before it's swapped in.
+func (m *Manager) parseAndValidate() (Template, error) {
+	t, err := ParseFile(m.name, m.path)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.Execute(ioutil.Discard, m.fixture); err != nil {
+		return nil, fmt.Errorf("error validating template %q against fixture data: %w", m.path, err)
+	}
+	return t, nil
+}
+
+// watch re-parses and re-validates the template file on every write or create event fsnotify reports for it,
+// swapping the result in on success.  Many editors replace a file on save rather than writing it in place, which
+// fsnotify reports as a Remove of the original path; watch re-adds the watch in that case so the Manager keeps
+// watching the replacement file instead of silently going dead.
+func (m *Manager) watch() {
+	defer func() { _ = m.watcher.Close() }()
+	for {
+		select {
+		case event, ok := <-m.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create) != 0 {
+				m.reload()
+			}
+			if event.Op&fsnotify.Remove != 0 {
+				_ = m.watcher.Add(m.path)
+			}
+		case err, ok := <-m.watcher.Errors:
+			if !ok {
+				return
+			}
+			if m.onError != nil {
+				m.onError(fmt.Errorf("error watching template %q: %w", m.path, err))
+			}
+		case <-m.done:
+			return
+		}
+	}
+}
+
+func (m *Manager) reload() {
+	t, err := m.parseAndValidate()
+	if err != nil {
+		if m.onError != nil {
+			m.onError(fmt.Errorf("error reloading template %q, keeping previous version: %w", m.path, err))
+		}
+		return
+	}
+	m.current.Store(t)
+}
+
+// Execute renders the current version of the template.  It takes no lock: callers always see either the template
+// NewManager started with or the most recent version that parsed and validated successfully, never a partially
+// swapped one.
+func (m *Manager) Execute(w io.Writer, data any) error {
+	return m.current.Load().(Template).Execute(w, data)
+}
+
+// Close stops watching the template file.  The last successfully loaded template keeps serving; Close only stops
+// picking up further changes.
+func (m *Manager) Close() error {
+	close(m.done)
+	return nil
+}
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"html/template"
 	"io/ioutil"
+	"mime"
 	"path"
 	"strings"
 	"time"
@@ -35,6 +36,54 @@ var funcMap = template.FuncMap{
 	"trimPrefix": func(p string, b string) string {
 		return strings.TrimPrefix(p, b)
 	},
+	"meta": func(v interface{}) map[string]string {
+		if withMetadata, ok := v.(interface{ Metadata() map[string]string }); ok {
+			return withMetadata.Metadata()
+		}
+		return nil
+	},
+	"humanizeBytes":  humanizeBytes,
+	"humanizeTime":   humanizeTime,
+	"relTime":        relTime,
+	"sortBy":         sortBy,
+	"filterByExt":    filterByExt,
+	"filterByPrefix": filterByPrefix,
+	"groupByDir":     groupByDir,
+	"hasSuffix": func(s string, suffix string) bool {
+		return strings.HasSuffix(s, suffix)
+	},
+	"hasPrefix": func(s string, prefix string) bool {
+		return strings.HasPrefix(s, prefix)
+	},
+	"basename": func(p string) string {
+		return path.Base(p)
+	},
+	"ext": func(p string) string {
+		return path.Ext(p)
+	},
+	"mimeType": func(name string) string {
+		if t := mime.TypeByExtension(path.Ext(name)); len(t) > 0 {
+			return t
+		}
+		return "application/octet-stream"
+	},
+	"dict": func(values ...interface{}) (map[string]interface{}, error) {
+		if len(values)%2 != 0 {
+			return nil, fmt.Errorf("dict requires an even number of arguments")
+		}
+		d := make(map[string]interface{}, len(values)/2)
+		for i := 0; i < len(values); i += 2 {
+			key, ok := values[i].(string)
+			if !ok {
+				return nil, fmt.Errorf("dict keys must be strings")
+			}
+			d[key] = values[i+1]
+		}
+		return d, nil
+	},
+	"list": func(values ...interface{}) []interface{} {
+		return values
+	},
 }
 
 func ParseFile(name string, p string) (Template, error) {